@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 	_ "github.com/joho/godotenv/autoload"
@@ -13,6 +14,113 @@ type (
 
 		TWCC struct {
 			APIKey string `env:"TWCC_API_KEY,required"`
+			// BaseURL, APIHost and APIVersion select the TWCC API gateway,
+			// e.g. to point the service at a fake gateway.
+			BaseURL    string `env:"TWCC_BASE_URL" envDefault:"https://apigateway.twcc.ai"`
+			APIHost    string `env:"TWCC_API_HOST" envDefault:"k8s-D-twcc"`
+			APIVersion string `env:"TWCC_API_VERSION" envDefault:"v3"`
+			// Project and JobFlavor are the defaults of twcc jobs and CCS
+			// created from a spec, Project is also where orphaned CCS are
+			// looked for.
+			Project   string `env:"TWCC_PROJECT,required"`
+			JobFlavor string `env:"TWCC_JOB_FLAVOR" envDefault:"1 GPU + 04 cores + 090GB memory"`
+			// CCSImage, CCSFlavor, CCSReplica, CCSPorts and CCSMounts are the
+			// defaults of inference jobs. CCSMounts maps the twcc storage to
+			// its mount path, e.g. gpfs01:/work/user,gpfs02:/home/user.
+			CCSImage   string            `env:"TWCC_CCS_IMAGE" envDefault:"tensorflow-23.08-tf2-py3:latest"`
+			CCSFlavor  string            `env:"TWCC_CCS_FLAVOR" envDefault:"1 GPU + 04 cores + 090GB memory"`
+			CCSReplica int               `env:"TWCC_CCS_REPLICA" envDefault:"1"`
+			CCSPorts   []int             `env:"TWCC_CCS_PORTS" envDefault:"5000"`
+			CCSMounts  map[string]string `env:"TWCC_CCS_MOUNTS"`
+			// CCSWorkers inference jobs are provisioned at once. A new CCS
+			// is polled every CCSPollInterval until it is ready and
+			// reachable, it fails after CCSReadyTimeout. A failed CCS is
+			// deleted and replaced, up to CCSAttempts CCS per job.
+			CCSWorkers      int           `env:"TWCC_CCS_WORKERS" envDefault:"4"`
+			CCSReadyTimeout time.Duration `env:"TWCC_CCS_READY_TIMEOUT" envDefault:"5m"`
+			CCSPollInterval time.Duration `env:"TWCC_CCS_POLL_INTERVAL" envDefault:"2s"`
+			CCSAttempts     int           `env:"TWCC_CCS_ATTEMPTS" envDefault:"3"`
+			// Inference jobs are leased for CCSDefaultTTL unless they ask
+			// for another TTL, capped at CCSMaxTTL. Expired jobs are torn
+			// down every CCSReapInterval.
+			CCSDefaultTTL   time.Duration `env:"TWCC_CCS_DEFAULT_TTL" envDefault:"30m"`
+			CCSMaxTTL       time.Duration `env:"TWCC_CCS_MAX_TTL" envDefault:"4h"`
+			CCSReapInterval time.Duration `env:"TWCC_CCS_REAP_INTERVAL" envDefault:"30s"`
+			// The warm pool keeps CCSPoolSize idle CCS with the default
+			// spec, or the size of the CCSPoolSchedule window the time of
+			// day falls into. It is topped up every CCSPoolInterval. The
+			// CCS of a deleted job goes back into the pool with
+			// CCSPoolRecycle, otherwise it is deleted.
+			CCSPoolSize     int           `env:"TWCC_CCS_POOL_SIZE" envDefault:"0"`
+			CCSPoolSchedule PoolSchedule  `env:"TWCC_CCS_POOL_SCHEDULE"`
+			CCSPoolInterval time.Duration `env:"TWCC_CCS_POOL_INTERVAL" envDefault:"1m"`
+			CCSPoolRecycle  bool          `env:"TWCC_CCS_POOL_RECYCLE" envDefault:"false"`
+			// Timeout bounds a TWCC call including its retries. Transient
+			// failures are retried up to RetryMax times with a jittered
+			// exponential backoff between RetryBaseDelay and RetryMaxDelay.
+			Timeout        time.Duration `env:"TWCC_TIMEOUT" envDefault:"30s"`
+			RetryMax       int           `env:"TWCC_RETRY_MAX" envDefault:"3"`
+			RetryBaseDelay time.Duration `env:"TWCC_RETRY_BASE_DELAY" envDefault:"500ms"`
+			RetryMaxDelay  time.Duration `env:"TWCC_RETRY_MAX_DELAY" envDefault:"10s"`
+			// Submitted jobs are polled every WatchMinInterval after their
+			// status changed, backing off up to WatchMaxInterval. A job that
+			// cannot be polled WatchMaxErrors times in a row fails.
+			WatchMinInterval time.Duration `env:"TWCC_WATCH_MIN_INTERVAL" envDefault:"3s"`
+			WatchMaxInterval time.Duration `env:"TWCC_WATCH_MAX_INTERVAL" envDefault:"1m"`
+			WatchMaxErrors   int           `env:"TWCC_WATCH_MAX_ERRORS" envDefault:"10"`
+		}
+
+		DB struct {
+			// Driver selects the job store: memory, sqlite or postgres.
+			Driver string `env:"DB_DRIVER" envDefault:"memory"`
+			DSN    DSN    `env:"DB_DSN"`
+		}
+
+		Scheduler struct {
+			// Number of training jobs allowed to run at once on each backend,
+			// further jobs wait in the queue.
+			DockerConcurrency int `env:"SCHEDULER_DOCKER_CONCURRENCY" envDefault:"2"`
+			TwccConcurrency   int `env:"SCHEDULER_TWCC_CONCURRENCY" envDefault:"4"`
+			// PlacementPolicy picks the backend of a queued job, one of
+			// local-first, remote-only, local-only, round-robin or least-loaded.
+			PlacementPolicy string `env:"SCHEDULER_PLACEMENT_POLICY" envDefault:"local-first"`
+			// LocalLimit caps the docker jobs of the local-first policy.
+			LocalLimit int `env:"SCHEDULER_LOCAL_LIMIT" envDefault:"2"`
+			// Preemption stops a lower priority docker job to make room for
+			// a higher priority one, the stopped job goes back into the queue.
+			Preemption bool `env:"SCHEDULER_PREEMPTION" envDefault:"false"`
+		}
+
+		GC struct {
+			// Interval between garbage collections, 0 only collects when
+			// triggered through the admin endpoint.
+			Interval time.Duration `env:"GC_INTERVAL" envDefault:"1h"`
+			// Retention keeps exited job containers, and with them their
+			// logs, for a while after they exited.
+			Retention time.Duration `env:"GC_RETENTION" envDefault:"24h"`
+			// DryRun only reports what would be removed.
+			DryRun bool `env:"GC_DRY_RUN" envDefault:"false"`
+		}
+
+		Events struct {
+			// ReplaySize is how many of the latest job events are kept for
+			// subscribers resuming with Last-Event-ID.
+			ReplaySize int `env:"EVENTS_REPLAY_SIZE" envDefault:"1024"`
+		}
+
+		Webhook struct {
+			// A webhook call fails after Timeout. Failed deliveries are
+			// retried with an exponential backoff from BaseDelay up to
+			// MaxDelay, until MaxAttempts calls failed.
+			Timeout     time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+			MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+			BaseDelay   time.Duration `env:"WEBHOOK_BASE_DELAY" envDefault:"5s"`
+			MaxDelay    time.Duration `env:"WEBHOOK_MAX_DELAY" envDefault:"10m"`
+		}
+
+		Registry struct {
+			// Credentials for private registries as JSON, see RegistryCredentials.
+			Credentials RegistryCredentials `env:"REGISTRY_CREDENTIALS"`
 		}
 
 		HTTP struct {
@@ -27,10 +135,8 @@ type (
 
 func NewConfig() (*Config, error) {
 	var cfg Config
-	env.Parse(&cfg)
-
 	if err := env.Parse(&cfg); err != nil {
-		fmt.Printf("%+v\n", err)
+		return nil, fmt.Errorf("config - NewConfig - env.Parse: %w", err)
 	}
 
 	fmt.Printf("%+v\n", cfg)
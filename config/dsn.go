@@ -0,0 +1,32 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DSN is the data source name of the job store, it may hold the password of
+// the database.
+type DSN string
+
+// _dsnPassword matches the password of a key/value postgres DSN, quoted or
+// not.
+var _dsnPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// String hides the password of the DSN, the config is logged on start up.
+func (d DSN) String() string {
+	s := string(d)
+	if strings.Contains(s, "://") {
+		if u, err := url.Parse(s); err == nil {
+			if q := u.Query(); q.Has("password") {
+				q.Set("password", "xxxxx")
+				u.RawQuery = q.Encode()
+			}
+
+			return u.Redacted()
+		}
+	}
+
+	return _dsnPassword.ReplaceAllString(s, "${1}xxxxx")
+}
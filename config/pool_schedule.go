@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// PoolSchedule is a comma separated list of windows like
+// 08:00-20:00=4,20:00-08:00=1, in the local time of the server.
+type PoolSchedule []entity.PoolWindow
+
+func (s *PoolSchedule) UnmarshalText(text []byte) error {
+	var schedule PoolSchedule
+	for _, item := range strings.Split(string(text), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		window, size, ok := strings.Cut(item, "=")
+		from, to, ok2 := strings.Cut(window, "-")
+		if !ok || !ok2 {
+			return fmt.Errorf("invalid pool window %q, want HH:MM-HH:MM=size", item)
+		}
+
+		var (
+			w   entity.PoolWindow
+			err error
+		)
+		if w.From, err = parseTimeOfDay(from); err != nil {
+			return fmt.Errorf("invalid pool window %q: %w", item, err)
+		}
+		if w.To, err = parseTimeOfDay(to); err != nil {
+			return fmt.Errorf("invalid pool window %q: %w", item, err)
+		}
+		if w.Size, err = strconv.Atoi(size); err != nil || w.Size < 0 {
+			return fmt.Errorf("invalid pool window %q: size must be a non-negative number", item)
+		}
+
+		schedule = append(schedule, w)
+	}
+
+	*s = schedule
+
+	return nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RegistryCredential authenticates image pulls from a private registry.
+type RegistryCredential struct {
+	Registry      string `json:"registry"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identityToken"`
+}
+
+// RegistryCredentials maps a name, which jobs may refer to, to the
+// credentials of a registry. It is read from JSON, e.g.
+// {"ghcr":{"registry":"ghcr.io","username":"bot","password":"..."}}.
+type RegistryCredentials map[string]RegistryCredential
+
+func (c *RegistryCredentials) UnmarshalText(text []byte) error {
+	credentials := make(map[string]RegistryCredential)
+	if err := json.Unmarshal(text, &credentials); err != nil {
+		return fmt.Errorf("invalid registry credentials: %w", err)
+	}
+
+	for name, cred := range credentials {
+		if cred.Registry == "" {
+			return fmt.Errorf("registry credential %q has no registry", name)
+		}
+	}
+
+	*c = credentials
+
+	return nil
+}
+
+// String lists the credentials without their secrets, the config is logged
+// on start up.
+func (c RegistryCredentials) String() string {
+	names := make([]string, 0, len(c))
+	for name, cred := range c {
+		names = append(names, name+":"+cred.Registry)
+	}
+	sort.Strings(names)
+
+	return "[" + strings.Join(names, " ") + "]"
+}
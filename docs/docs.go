@@ -15,6 +15,165 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/gc": {
+            "get": {
+                "description": "show the report of the last garbage collection, periodic or triggered",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "show last garbage collection",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.GCReport"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "remove exited job containers past their retention, dangling images pulled for jobs\nand CCS no job owns, resources that could not be listed or removed are reported as errors",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "collect garbage",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "only report what would be removed",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.GCReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "stream the state transitions of training and inference jobs as Server-Sent Events,\none \"transition\" event per transition. Events after Last-Event-ID are replayed if they\nare still buffered. The stream ends if the client falls behind, it can resume from the last event",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "stream job events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "resume after this event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "resume after this event, for clients that cannot set headers",
+                        "name": "lastEventId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events of these jobs, comma separated",
+                        "name": "jobId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events of these job kinds, training or inference",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only transitions into these states, comma separated",
+                        "name": "state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.JobEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/ws": {
+            "get": {
+                "description": "stream the state transitions of training and inference jobs as JSON messages over a WebSocket,\nwith the same filters and resume as the event stream",
+                "tags": [
+                    "events"
+                ],
+                "summary": "stream job events over WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "resume after this event",
+                        "name": "lastEventId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events of these jobs, comma separated",
+                        "name": "jobId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events of these job kinds, training or inference",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only transitions into these states, comma separated",
+                        "name": "state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/entity.JobEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/inference-jobs": {
             "get": {
                 "description": "list all inference jobs",
@@ -45,7 +204,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "create inference job",
+                "description": "create inference job, the job is provisioned in the background\nand reports its entry point once it is running",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,12 +228,18 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "$ref": "#/definitions/v1.createInferenceJobResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -159,24 +324,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/training-jobs/all": {
+        "/inference-jobs/{id}/deliveries": {
             "get": {
-                "description": "list all training jobs",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "list the calls of the callback of an inference job, one delivery per terminal transition\nwith every attempt to deliver it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "training-jobs"
+                    "inference-jobs"
+                ],
+                "summary": "list inference job callback deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "list all training jobs",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.getJobResponse"
+                            "$ref": "#/definitions/v1.getDeliveriesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
                         }
                     },
                     "500": {
@@ -188,9 +365,109 @@ const docTemplate = `{
                 }
             }
         },
-        "/training-jobs/create": {
+        "/inference-jobs/{id}/proxy/{path}": {
+            "get": {
+                "description": "forward a request to the entry point of a running inference job,\nstreamed responses and WebSocket upgrades are passed through",
+                "tags": [
+                    "inference-jobs"
+                ],
+                "summary": "proxy to inference job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path on the entry point",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "create training job",
+                "description": "forward a request to the entry point of a running inference job,\nstreamed responses and WebSocket upgrades are passed through",
+                "tags": [
+                    "inference-jobs"
+                ],
+                "summary": "proxy to inference job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path on the entry point",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/inference-jobs/{id}/renew": {
+            "post": {
+                "description": "extend the lease of an inference job before it expires",
                 "consumes": [
                     "application/json"
                 ],
@@ -198,17 +475,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "training-jobs"
+                    "inference-jobs"
                 ],
-                "summary": "create training job",
+                "summary": "renew inference job",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "description": "request",
                         "name": "req",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.createTrainingJobRequest"
+                            "$ref": "#/definitions/v1.renewInferenceJobRequest"
                         }
                     }
                 ],
@@ -216,7 +499,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.createTrainingJobResponse"
+                            "$ref": "#/definitions/v1.renewInferenceJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
                         }
                     },
                     "500": {
@@ -228,9 +529,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/training-jobs/{id}": {
+        "/training-jobs/all": {
             "get": {
-                "description": "get training job",
+                "description": "list all training jobs",
                 "consumes": [
                     "application/json"
                 ],
@@ -240,16 +541,7 @@ const docTemplate = `{
                 "tags": [
                     "training-jobs"
                 ],
-                "summary": "get training job",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Job ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "list all training jobs",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -265,13 +557,297 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "entity.GenericJob": {
-            "type": "object",
-            "properties": {
-                "jobId": {
+        },
+        "/training-jobs/create": {
+            "post": {
+                "description": "create training job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "training-jobs"
+                ],
+                "summary": "create training job",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.createTrainingJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.createTrainingJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/training-jobs/{id}": {
+            "get": {
+                "description": "get training job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "training-jobs"
+                ],
+                "summary": "get training job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.getJobResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "cancel a queued or running training job, its container or twcc job is stopped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "training-jobs"
+                ],
+                "summary": "cancel training job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.sResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/training-jobs/{id}/deliveries": {
+            "get": {
+                "description": "list the calls of the callback of a training job, one delivery per terminal transition\nwith every attempt to deliver it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "training-jobs"
+                ],
+                "summary": "list training job callback deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.getDeliveriesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/training-jobs/{id}/logs": {
+            "get": {
+                "description": "stream the container logs of a docker training job as chunked plain text,\nor as Server-Sent Events with one \"log\" event per line if text/event-stream is accepted",
+                "produces": [
+                    "text/plain",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "training-jobs"
+                ],
+                "summary": "stream training job logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "keep streaming new log lines",
+                        "name": "follow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "timestamp or relative duration like 10m",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "number of lines from the end, or all",
+                        "name": "tail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "prefix every line with its timestamp",
+                        "name": "timestamps",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.eResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entity.GCReport": {
+            "type": "object",
+            "properties": {
+                "dryRun": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "finishedAt": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:02Z"
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Orphan"
+                    }
+                },
+                "startedAt": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:00Z"
+                }
+            }
+        },
+        "entity.GenericJob": {
+            "type": "object",
+            "properties": {
+                "exitCode": {
+                    "description": "ExitCode and OOMKilled are set once the container of a docker job exited.",
+                    "type": "integer",
+                    "example": 137
+                },
+                "failureReason": {
+                    "description": "FailureReason explains why a job ended up failed.",
+                    "type": "string",
+                    "example": "container was killed by the OOM killer"
+                },
+                "jobId": {
                     "type": "string",
                     "example": "12345"
                 },
@@ -280,21 +856,452 @@ const docTemplate = `{
                     "example": "name"
                 },
                 "jobStatus": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.JobState"
+                        }
+                    ],
+                    "example": "running"
+                },
+                "oomKilled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "phase": {
+                    "description": "Phase and PullProgress report the progress of a provisioning job.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.JobPhase"
+                        }
+                    ],
+                    "example": "pulling image"
+                },
+                "priorityClass": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.PriorityClass"
+                        }
+                    ],
+                    "example": "normal"
+                },
+                "pullProgress": {
+                    "$ref": "#/definitions/entity.ImagePullProgress"
+                },
+                "queuePosition": {
+                    "description": "QueuePosition is the 1-based position of a queued job, it is computed\non read and zero for jobs that are not waiting in the queue.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "transitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.JobTransition"
+                    }
+                }
+            }
+        },
+        "entity.ImagePullProgress": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "integer",
+                    "example": 104857600
+                },
+                "layers": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "layersDone": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 524288000
+                }
+            }
+        },
+        "entity.InferenceMount": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string",
+                    "example": "/work/yjack0000"
+                },
+                "storage": {
                     "type": "string",
-                    "example": "jobStatus"
+                    "enum": [
+                        "gpfs01",
+                        "gpfs02"
+                    ],
+                    "example": "gpfs01"
                 }
             }
         },
-        "v1.createInferenceJobRequest": {
-            "type": "object"
+        "entity.JobEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:00Z"
+                },
+                "failureReason": {
+                    "description": "FailureReason is set for transitions into a failed state.",
+                    "type": "string"
+                },
+                "from": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.JobState"
+                        }
+                    ],
+                    "example": "running"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1698840000000
+                },
+                "jobId": {
+                    "type": "string",
+                    "example": "0b9b7a43-6f3d-4c1e-9d67-1f0e3a1c2b4d"
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.JobKind"
+                        }
+                    ],
+                    "example": "training"
+                },
+                "to": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.JobState"
+                        }
+                    ],
+                    "example": "succeeded"
+                }
+            }
         },
-        "v1.createInferenceJobResponse": {
+        "entity.JobKind": {
+            "type": "string",
+            "enum": [
+                "training",
+                "inference"
+            ],
+            "x-enum-varnames": [
+                "JobKindTraining",
+                "JobKindInference"
+            ]
+        },
+        "entity.JobPhase": {
+            "type": "string",
+            "enum": [
+                "pulling image",
+                "creating container"
+            ],
+            "x-enum-varnames": [
+                "JobPhasePullingImage",
+                "JobPhaseCreatingContainer"
+            ]
+        },
+        "entity.JobState": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "queued",
+                "provisioning",
+                "running",
+                "succeeded",
+                "failed",
+                "cancelled",
+                "expired",
+                "lost"
+            ],
+            "x-enum-varnames": [
+                "JobStatePending",
+                "JobStateQueued",
+                "JobStateProvisioning",
+                "JobStateRunning",
+                "JobStateSucceeded",
+                "JobStateFailed",
+                "JobStateCancelled",
+                "JobStateExpired",
+                "JobStateLost"
+            ]
+        },
+        "entity.JobTransition": {
             "type": "object",
             "properties": {
-                "entryPoint": {
+                "at": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:00Z"
+                },
+                "from": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.JobState"
+                        }
+                    ],
+                    "example": "pending"
+                },
+                "to": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.JobState"
+                        }
+                    ],
+                    "example": "provisioning"
+                }
+            }
+        },
+        "entity.Mount": {
+            "type": "object",
+            "properties": {
+                "readOnly": {
+                    "type": "boolean"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "/data/datasets"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "/datasets"
+                },
+                "type": {
+                    "enum": [
+                        "bind",
+                        "volume"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.MountType"
+                        }
+                    ],
+                    "example": "bind"
+                }
+            }
+        },
+        "entity.MountType": {
+            "type": "string",
+            "enum": [
+                "bind",
+                "volume"
+            ],
+            "x-enum-varnames": [
+                "MountTypeBind",
+                "MountTypeVolume"
+            ]
+        },
+        "entity.Orphan": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "4f66ad9a0b2e"
+                },
+                "jobId": {
+                    "type": "string",
+                    "example": "0b9b7a43-6f3d-4c1e-9d67-1f0e3a1c2b4d"
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ResourceKind"
+                        }
+                    ],
+                    "example": "container"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "job is succeeded"
+                }
+            }
+        },
+        "entity.PriorityClass": {
+            "type": "string",
+            "enum": [
+                "low",
+                "normal",
+                "high",
+                "urgent"
+            ],
+            "x-enum-varnames": [
+                "PriorityLow",
+                "PriorityNormal",
+                "PriorityHigh",
+                "PriorityUrgent"
+            ]
+        },
+        "entity.ProvisioningAttempt": {
+            "type": "object",
+            "properties": {
+                "ccsId": {
+                    "type": "string",
+                    "example": "12345"
+                },
+                "failedAt": {
+                    "type": "string",
+                    "example": "2023-11-01T12:05:00Z"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "inference job did not become ready: ccs 12345 timed out in status Pending"
+                },
+                "rollbackError": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:00Z"
+                },
+                "step": {
+                    "type": "string",
+                    "example": "wait for entry point"
+                }
+            }
+        },
+        "entity.ResourceKind": {
+            "type": "string",
+            "enum": [
+                "container",
+                "ccs",
+                "image"
+            ],
+            "x-enum-varnames": [
+                "ResourceContainer",
+                "ResourceTwccCCS",
+                "ResourceImage"
+            ]
+        },
+        "entity.WebhookAttempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:00Z"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "statusCode": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "entity.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.WebhookAttempt"
+                    }
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:00Z"
+                },
+                "event": {
+                    "$ref": "#/definitions/entity.JobEvent"
+                },
+                "eventId": {
+                    "type": "integer",
+                    "example": 1698840000000
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f0c6f7e-2b1d-4a43-9c1e-8d3b2a7f6e10"
+                },
+                "jobId": {
+                    "type": "string",
+                    "example": "0b9b7a43-6f3d-4c1e-9d67-1f0e3a1c2b4d"
+                },
+                "nextAttemptAt": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:05Z"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.WebhookDeliveryStatus"
+                        }
+                    ],
+                    "example": "delivered"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/jobs"
+                }
+            }
+        },
+        "entity.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "WebhookDeliveryPending",
+                "WebhookDeliveryDelivered",
+                "WebhookDeliveryFailed"
+            ]
+        },
+        "v1.createInferenceJobRequest": {
+            "type": "object",
+            "properties": {
+                "callback": {
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/jobs"
+                },
+                "callbackSecret": {
+                    "type": "string"
+                },
+                "flavor": {
+                    "type": "string",
+                    "example": "1 GPU + 04 cores + 090GB memory"
+                },
+                "image": {
                     "type": "string",
-                    "example": "http://localhost:8080"
+                    "example": "tensorflow-23.08-tf2-py3:latest"
                 },
+                "mounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.InferenceMount"
+                    }
+                },
+                "ports": {
+                    "description": "Ports are exposed on a public IP, the first one is the entry point.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        5000
+                    ]
+                },
+                "project": {
+                    "type": "string",
+                    "example": "65662"
+                },
+                "replica": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ttl": {
+                    "description": "TTL is how long the job is kept, like 30m, capped by the configured\nmaximum. The configured default is used if left empty.",
+                    "type": "string",
+                    "example": "30m"
+                }
+            }
+        },
+        "v1.createInferenceJobResponse": {
+            "type": "object",
+            "properties": {
                 "jobId": {
                     "type": "string",
                     "example": "12345"
@@ -304,13 +1311,113 @@ const docTemplate = `{
         "v1.createTrainingJobRequest": {
             "type": "object",
             "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "train.py",
+                        "--epochs=3"
+                    ]
+                },
+                "callback": {
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/jobs"
+                },
+                "callbackSecret": {
+                    "type": "string"
+                },
+                "command": {
+                    "description": "Command replaces the entrypoint of the image and Args its arguments.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "python"
+                    ]
+                },
+                "cpus": {
+                    "type": "number",
+                    "example": 2
+                },
                 "dockerImageName": {
                     "type": "string",
                     "example": "yjack0000cs12/llm-training:latest"
                 },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "flavor": {
+                    "description": "Flavor and Project override the configured defaults on twcc.",
+                    "type": "string",
+                    "example": "1 GPU + 04 cores + 090GB memory"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "memory": {
+                    "description": "Memory and ShmSize accept sizes like 512m or 4g.",
+                    "type": "string",
+                    "example": "4g"
+                },
+                "mounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Mount"
+                    }
+                },
+                "placementPolicy": {
+                    "description": "PlacementPolicy overrides the configured placement policy.",
+                    "type": "string",
+                    "enum": [
+                        "local-first",
+                        "remote-only",
+                        "local-only",
+                        "round-robin",
+                        "least-loaded"
+                    ],
+                    "example": "local-first"
+                },
+                "priorityClass": {
+                    "description": "PriorityClass is one of low, normal, high or urgent, defaults to normal.",
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "normal"
+                },
+                "project": {
+                    "type": "string",
+                    "example": "65662"
+                },
+                "registryCredential": {
+                    "description": "RegistryCredential names the configured credentials to pull the image\nwith, by default they are picked by the registry of the image.",
+                    "type": "string",
+                    "example": "ghcr"
+                },
+                "shmSize": {
+                    "type": "string",
+                    "example": "1g"
+                },
                 "twccJobId": {
+                    "description": "TwccJobId runs a twcc job created beforehand, without it a twcc job is\ncreated from the spec if the job is placed on twcc.",
                     "type": "string",
                     "example": "237139"
+                },
+                "workingDir": {
+                    "type": "string",
+                    "example": "/workspace"
                 }
             }
         },
@@ -332,11 +1439,47 @@ const docTemplate = `{
                 }
             }
         },
+        "v1.getDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "callback": {
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/jobs"
+                },
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.WebhookDelivery"
+                    }
+                }
+            }
+        },
         "v1.getInferenceJobResponse": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "description": "Attempts lists the failed attempts to provision the job, the reason\nof the final failure is the failureReason of the job.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ProvisioningAttempt"
+                    }
+                },
+                "entryPoint": {
+                    "description": "EntryPoint and ExpiresAt are set once the job is running.",
+                    "type": "string",
+                    "example": "203.0.113.1:50002"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2023-11-01T12:30:00Z"
+                },
                 "job": {
                     "$ref": "#/definitions/entity.GenericJob"
+                },
+                "proxyRequests": {
+                    "description": "ProxyRequests counts the requests proxied to the job since the\nserver started.",
+                    "type": "integer",
+                    "example": 42
                 }
             }
         },
@@ -359,6 +1502,25 @@ const docTemplate = `{
                 }
             }
         },
+        "v1.renewInferenceJobRequest": {
+            "type": "object",
+            "properties": {
+                "ttl": {
+                    "description": "TTL extends the lease from now, like 30m, capped by the configured\nmaximum. The job is renewed by its own ttl if left empty.",
+                    "type": "string",
+                    "example": "30m"
+                }
+            }
+        },
+        "v1.renewInferenceJobResponse": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2023-11-01T12:30:00Z"
+                }
+            }
+        },
         "v1.sResponse": {
             "type": "object",
             "properties": {
@@ -1,9 +1,11 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 
 	"github.com/docker/docker/client"
@@ -13,7 +15,10 @@ import (
 	restful "golang_backend_template/internal/controller/restful"
 	adapter "golang_backend_template/internal/infra/adapter"
 	memo "golang_backend_template/internal/infra/memo"
+	"golang_backend_template/internal/infra/sqlstore"
+	"golang_backend_template/internal/usecase/entity"
 	"golang_backend_template/internal/usecase/impl"
+	"golang_backend_template/internal/usecase/ports"
 	"golang_backend_template/pkg/httpserver"
 	"golang_backend_template/pkg/logger"
 )
@@ -26,22 +31,159 @@ func Run(cfg *config.Config) {
 		l.Error(fmt.Errorf("app - Run - client.NewClientWithOpts: %w", err))
 	}
 
-	trainingJobManager := impl.NewTrainingJobManager(
-		memo.NewTrainingJobsMemory(),
-		adapter.NewDockerAdapter(cli),
-		adapter.NewTwccAdapter(cfg.TWCC.APIKey),
+	var (
+		trainingRepo  ports.TrainingJobsRepo = memo.NewTrainingJobsMemory()
+		inferenceRepo ports.InferenceJobRepo = memo.NewInferenceJobsMemory()
+		webhookRepo   ports.WebhookRepo      = memo.NewWebhooksMemory()
 	)
 
+	if cfg.DB.Driver != "memory" {
+		db, err := sqlstore.Open(cfg.DB.Driver, string(cfg.DB.DSN))
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - sqlstore.Open: %w", err))
+		}
+		defer db.Close()
+
+		trainingRepo = sqlstore.NewTrainingJobsStore(db)
+		inferenceRepo = sqlstore.NewInferenceJobsStore(db)
+		webhookRepo = sqlstore.NewWebhooksStore(db)
+	}
+
+	twccConfig := adapter.TwccConfig{
+		BaseURL:    cfg.TWCC.BaseURL,
+		APIHost:    cfg.TWCC.APIHost,
+		APIVersion: cfg.TWCC.APIVersion,
+		APIKey:     cfg.TWCC.APIKey,
+		Project:    cfg.TWCC.Project,
+		JobFlavor:  cfg.TWCC.JobFlavor,
+		Timeout:    cfg.TWCC.Timeout,
+		Retry: adapter.RetryConfig{
+			MaxRetries: cfg.TWCC.RetryMax,
+			BaseDelay:  cfg.TWCC.RetryBaseDelay,
+			MaxDelay:   cfg.TWCC.RetryMaxDelay,
+		},
+	}
+
+	registryCredentials := make(map[string]adapter.RegistryCredential, len(cfg.Registry.Credentials))
+	for name, cred := range cfg.Registry.Credentials {
+		registryCredentials[name] = adapter.RegistryCredential(cred)
+	}
+
+	twccAdapter := adapter.NewTwccAdapter(twccConfig)
+	twccJobWatcher := impl.NewTwccJobWatcher(twccAdapter, impl.TwccWatcherConfig{
+		MinInterval: cfg.TWCC.WatchMinInterval,
+		MaxInterval: cfg.TWCC.WatchMaxInterval,
+		MaxErrors:   cfg.TWCC.WatchMaxErrors,
+	})
+
+	eventBus := impl.NewEventBus(cfg.Events.ReplaySize)
+
+	trainingJobManager, err := impl.NewTrainingJobManager(
+		trainingRepo,
+		adapter.NewDockerAdapter(cli, registryCredentials),
+		twccAdapter,
+		twccJobWatcher,
+		eventBus,
+		impl.SchedulerConfig{
+			DockerConcurrency: cfg.Scheduler.DockerConcurrency,
+			TwccConcurrency:   cfg.Scheduler.TwccConcurrency,
+			PlacementPolicy:   cfg.Scheduler.PlacementPolicy,
+			LocalLimit:        cfg.Scheduler.LocalLimit,
+			Preemption:        cfg.Scheduler.Preemption,
+		},
+	)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - impl.NewTrainingJobManager: %w", err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inferenceDefaults := entity.InferenceSpec{
+		Image:   cfg.TWCC.CCSImage,
+		Flavor:  cfg.TWCC.CCSFlavor,
+		Replica: cfg.TWCC.CCSReplica,
+		Ports:   cfg.TWCC.CCSPorts,
+	}
+	for storage, path := range cfg.TWCC.CCSMounts {
+		inferenceDefaults.Mounts = append(inferenceDefaults.Mounts, entity.InferenceMount{Storage: storage, Path: path})
+	}
+	sort.Slice(inferenceDefaults.Mounts, func(i, j int) bool {
+		return inferenceDefaults.Mounts[i].Storage < inferenceDefaults.Mounts[j].Storage
+	})
+	if err := inferenceDefaults.Validate(); err != nil {
+		l.Fatal(fmt.Errorf("app - Run - inferenceDefaults.Validate: %w", err))
+	}
+
+	inferenceConfig := impl.InferenceConfig{
+		Defaults:     inferenceDefaults,
+		Workers:      cfg.TWCC.CCSWorkers,
+		ReadyTimeout: cfg.TWCC.CCSReadyTimeout,
+		PollInterval: cfg.TWCC.CCSPollInterval,
+		Attempts:     cfg.TWCC.CCSAttempts,
+		DefaultTTL:   cfg.TWCC.CCSDefaultTTL,
+		MaxTTL:       cfg.TWCC.CCSMaxTTL,
+		ReapInterval: cfg.TWCC.CCSReapInterval,
+	}
+
+	inferencePool := impl.NewInferencePool(twccAdapter, inferenceDefaults, impl.InferencePoolConfig{
+		Size:     cfg.TWCC.CCSPoolSize,
+		Schedule: cfg.TWCC.CCSPoolSchedule,
+		Interval: cfg.TWCC.CCSPoolInterval,
+		Recycle:  cfg.TWCC.CCSPoolRecycle,
+	}, inferenceConfig, l)
+
 	inferenceJobManager := impl.NewInferenceJobManager(
-		memo.NewInferenceJobsMemory(),
-		adapter.NewTwccAdapter(cfg.TWCC.APIKey),
+		inferenceRepo,
+		twccAdapter,
+		inferencePool,
+		eventBus,
+		inferenceConfig,
+		l,
 	)
 
+	// Dispatch webhooks before reconciling, so jobs lost while the service
+	// was down call their webhooks too.
+	webhookDispatcher := impl.NewWebhookDispatcher(webhookRepo, eventBus, impl.WebhookConfig{
+		Timeout:     cfg.Webhook.Timeout,
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+		BaseDelay:   cfg.Webhook.BaseDelay,
+		MaxDelay:    cfg.Webhook.MaxDelay,
+	}, l)
+	webhookDispatcher.Start(ctx)
+
+	// Reconcile before starting, so the managers resume the adopted jobs.
+	reconciler := impl.NewReconciler(trainingJobManager, inferenceJobManager)
+	report, err := reconciler.Run(ctx)
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - reconciler.Run: %w", err))
+	}
+	l.Info("app - Run - reconciled jobs: %d adopted, %d lost, %d orphans",
+		len(report.Adopted), len(report.Lost), len(report.Orphans))
+	for _, o := range report.Orphans {
+		l.Warn("app - Run - orphaned %s %s of job %s: %s", o.Kind, o.ID, o.JobID, o.Reason)
+	}
+
+	twccJobWatcher.Start(ctx)
+	trainingJobManager.Start(ctx)
+	inferencePool.Start(ctx)
+	inferenceJobManager.Start(ctx)
+
+	garbageCollector := impl.NewGarbageCollector(trainingJobManager, inferenceJobManager, reconciler, impl.GCConfig{
+		Interval:  cfg.GC.Interval,
+		Retention: cfg.GC.Retention,
+		DryRun:    cfg.GC.DryRun,
+	})
+	garbageCollector.Start(ctx)
+
 	handler := gin.New()
 	restful.SetupRouter(handler,
 		l,
 		trainingJobManager,
-		inferenceJobManager)
+		inferenceJobManager,
+		garbageCollector,
+		eventBus,
+		webhookDispatcher)
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
 	interrupt := make(chan os.Signal, 1)
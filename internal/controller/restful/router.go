@@ -19,7 +19,7 @@ import (
 // @description swagger test example
 // @schemes http https
 // @BasePath /v1
-func SetupRouter(handler *gin.Engine, l logger.Interface, trainingJobManager usecase.TrainingJobRequester, inferenceJobManager usecase.InferenceJobRequester) {
+func SetupRouter(handler *gin.Engine, l logger.Interface, trainingJobManager usecase.TrainingJobRequester, inferenceJobManager usecase.InferenceJobRequester, garbageCollector usecase.GarbageCollectionRequester, eventBus usecase.JobEventRequester, webhookDispatcher usecase.WebhookRequester, proxyMiddleware ...gin.HandlerFunc) {
 	handler.Use(gin.Logger())
 	handler.Use(gin.Recovery())
 
@@ -30,7 +30,9 @@ func SetupRouter(handler *gin.Engine, l logger.Interface, trainingJobManager use
 
 	h := handler.Group("/v1")
 	{
-		v1.InitTrainingJobRoutes(h, trainingJobManager, l)
-		v1.InitInferenceJobRoutes(h, inferenceJobManager, l)
+		v1.InitTrainingJobRoutes(h, trainingJobManager, webhookDispatcher, l)
+		v1.InitInferenceJobRoutes(h, inferenceJobManager, webhookDispatcher, l, proxyMiddleware...)
+		v1.InitAdminRoutes(h, garbageCollector, l)
+		v1.InitEventRoutes(h, eventBus, l)
 	}
 }
@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/pkg/logger"
+)
+
+type AdminController struct {
+	gc usecase.GarbageCollectionRequester
+	l  logger.Interface
+}
+
+func InitAdminRoutes(handler *gin.RouterGroup, gc usecase.GarbageCollectionRequester, l logger.Interface) {
+	r := &AdminController{gc, l}
+
+	h := handler.Group("/admin")
+	{
+		h.POST("/gc", r.collect)
+		h.GET("/gc", r.lastCollection)
+	}
+}
+
+// @Summary     collect garbage
+// @Description remove exited job containers past their retention, dangling images pulled for jobs
+// @Description and CCS no job owns, resources that could not be listed or removed are reported as errors
+// @Tags  	    admin
+// @Produce     json
+// @Param       dryRun  query  bool  false  "only report what would be removed"
+// @Success     200 {object} entity.GCReport
+// @Router      /admin/gc [post]
+func (r *AdminController) collect(c *gin.Context) {
+	report, err := r.gc.Collect(c.Request.Context(), queryBool(c, "dryRun"))
+	if err != nil {
+		r.l.Error(err, "http - v1 - collect")
+	}
+
+	c.JSON(200, report)
+}
+
+// @Summary     show last garbage collection
+// @Description show the report of the last garbage collection, periodic or triggered
+// @Tags  	    admin
+// @Produce     json
+// @Success     200 {object} entity.GCReport
+// @Failure     404 {object} eResponse
+// @Router      /admin/gc [get]
+func (r *AdminController) lastCollection(c *gin.Context) {
+	report, ok := r.gc.LastReport()
+	if !ok {
+		errorResponse(c, 404, "no garbage collection ran yet")
+
+		return
+	}
+
+	c.JSON(200, report)
+}
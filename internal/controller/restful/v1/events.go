@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/pkg/logger"
+)
+
+// _eventKeepAlive is how often an idle event stream is written to, so
+// proxies do not close it.
+const _eventKeepAlive = 30 * time.Second
+
+type EventController struct {
+	u        usecase.JobEventRequester
+	l        logger.Interface
+	upgrader websocket.Upgrader
+}
+
+func InitEventRoutes(handler *gin.RouterGroup, u usecase.JobEventRequester, l logger.Interface) {
+	r := &EventController{u: u, l: l}
+
+	h := handler.Group("/events")
+	{
+		h.GET("", r.stream)
+		h.GET("/ws", r.socket)
+	}
+}
+
+// queryList collects a query parameter given repeatedly or comma separated.
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, v := range c.QueryArray(key) {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+
+	return values
+}
+
+// subscribe parses the filter and the event to resume after, and subscribes
+// to the job events. It writes an error response and returns false for an
+// invalid request.
+func (r *EventController) subscribe(c *gin.Context) (<-chan entity.JobEvent, bool) {
+	filter := usecase.JobEventFilter{JobIDs: queryList(c, "jobId")}
+	for _, kind := range queryList(c, "kind") {
+		switch k := entity.JobKind(kind); k {
+		case entity.JobKindTraining, entity.JobKindInference:
+			filter.Kinds = append(filter.Kinds, k)
+		default:
+			errorResponse(c, 400, "kind must be training or inference")
+
+			return nil, false
+		}
+	}
+	for _, state := range queryList(c, "state") {
+		filter.States = append(filter.States, entity.JobState(state))
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	var after uint64
+	if lastEventID != "" {
+		var err error
+		if after, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			errorResponse(c, 400, "invalid last event id")
+
+			return nil, false
+		}
+	}
+
+	return r.u.Subscribe(c.Request.Context(), filter, after), true
+}
+
+// @Summary     stream job events
+// @Description stream the state transitions of training and inference jobs as Server-Sent Events,
+// @Description one "transition" event per transition. Events after Last-Event-ID are replayed if they
+// @Description are still buffered. The stream ends if the client falls behind, it can resume from the last event
+// @Tags  	    events
+// @Produce     text/event-stream
+// @Param       Last-Event-ID  header  string  false  "resume after this event"
+// @Param       lastEventId    query   string  false  "resume after this event, for clients that cannot set headers"
+// @Param       jobId          query   string  false  "only events of these jobs, comma separated"
+// @Param       kind           query   string  false  "only events of these job kinds, training or inference"
+// @Param       state          query   string  false  "only transitions into these states, comma separated"
+// @Success     200 {object} entity.JobEvent
+// @Failure     400 {object} eResponse
+// @Router      /events [get]
+func (r *EventController) stream(c *gin.Context) {
+	events, ok := r.subscribe(c)
+	if !ok {
+		return
+	}
+
+	disableWriteTimeout(c)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(200)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(_eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			c.Render(-1, sse.Event{Id: strconv.FormatUint(e.ID, 10), Event: "transition", Data: e})
+		case <-keepAlive.C:
+			if _, err := c.Writer.WriteString(":keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// @Summary     stream job events over WebSocket
+// @Description stream the state transitions of training and inference jobs as JSON messages over a WebSocket,
+// @Description with the same filters and resume as the event stream
+// @Tags  	    events
+// @Param       lastEventId  query  string  false  "resume after this event"
+// @Param       jobId        query  string  false  "only events of these jobs, comma separated"
+// @Param       kind         query  string  false  "only events of these job kinds, training or inference"
+// @Param       state        query  string  false  "only transitions into these states, comma separated"
+// @Success     101 {object} entity.JobEvent
+// @Failure     400 {object} eResponse
+// @Router      /events/ws [get]
+func (r *EventController) socket(c *gin.Context) {
+	events, ok := r.subscribe(c)
+	if !ok {
+		return
+	}
+
+	conn, err := r.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader already answered the request.
+		r.l.Error(err, "http - v1 - socket")
+
+		return
+	}
+	defer conn.Close()
+
+	// Clients do not send anything, reading notices when they go away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(_eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case e, ok := <-events:
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell behind"))
+
+				return
+			}
+
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,11 +16,15 @@ import (
 
 type InferenceJobController struct {
 	u usecase.InferenceJobRequester
+	w usecase.WebhookRequester
 	l logger.Interface
 }
 
-func InitInferenceJobRoutes(handler *gin.RouterGroup, u usecase.InferenceJobRequester, l logger.Interface) {
-	c := &InferenceJobController{u, l}
+// InitInferenceJobRoutes registers the inference job routes, proxyMiddleware
+// runs in front of the proxy to the entry points of the jobs, e.g. to
+// authenticate its clients.
+func InitInferenceJobRoutes(handler *gin.RouterGroup, u usecase.InferenceJobRequester, w usecase.WebhookRequester, l logger.Interface, proxyMiddleware ...gin.HandlerFunc) {
+	c := &InferenceJobController{u, w, l}
 
 	h := handler.Group("/inference-jobs")
 	{
@@ -25,24 +32,75 @@ func InitInferenceJobRoutes(handler *gin.RouterGroup, u usecase.InferenceJobRequ
 		h.POST("", c.create)
 		h.GET(":id", c.get)
 		h.DELETE(":id", c.delete)
+		h.POST(":id/renew", c.renew)
+		h.GET(":id/deliveries", c.deliveries)
+	}
+
+	p := h.Group(":id/proxy", proxyMiddleware...)
+	{
+		p.Any("*path", c.proxy)
 	}
 }
 
+// createInferenceJobRequest describes the CCS of the job, fields left empty
+// take the configured defaults.
 type createInferenceJobRequest struct {
+	Image   string `json:"image" example:"tensorflow-23.08-tf2-py3:latest"`
+	Flavor  string `json:"flavor" example:"1 GPU + 04 cores + 090GB memory"`
+	Replica int    `json:"replica" example:"1"`
+	// Ports are exposed on a public IP, the first one is the entry point.
+	Ports   []int                   `json:"ports" example:"5000"`
+	Mounts  []entity.InferenceMount `json:"mounts"`
+	Project string                  `json:"project" example:"65662"`
+	// TTL is how long the job is kept, like 30m, capped by the configured
+	// maximum. The configured default is used if left empty.
+	TTL string `json:"ttl" example:"30m"`
+
+	callbackRequest
+}
+
+// parseTTL parses a duration like 30m, an empty ttl is 0.
+func parseTTL(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("ttl %s is not positive", s)
+	}
+
+	return ttl, nil
+}
+
+// spec turns the request into the spec of the job.
+func (req createInferenceJobRequest) spec() entity.InferenceSpec {
+	return entity.InferenceSpec{
+		Image:   req.Image,
+		Flavor:  req.Flavor,
+		Replica: req.Replica,
+		Ports:   req.Ports,
+		Mounts:  req.Mounts,
+		Project: req.Project,
+	}
 }
 
 type createInferenceJobResponse struct {
-	JobId      string `json:"jobId" example:"12345"`
-	EntryPoint string `json:"entryPoint" example:"http://localhost:8080"`
+	JobId string `json:"jobId" example:"12345"`
 }
 
 // @Summary     create inference job
-// @Description create inference job
+// @Description create inference job, the job is provisioned in the background
+// @Description and reports its entry point once it is running
 // @ID          create
 // @Tags  	    inference-jobs
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} createInferenceJobResponse
+// @Success     202 {object} createInferenceJobResponse
+// @Failure     400 {object} eResponse
 // @Failure     500 {object} eResponse
 // @Router      /inference-jobs [post]
 // @Param       req body createInferenceJobRequest true "request"
@@ -55,32 +113,50 @@ func (r *InferenceJobController) create(c *gin.Context) {
 		return
 	}
 
-	job := entity.GenericJob{
-		ID:     uuid.New().String(),
-		Name:   "inference job",
-		Status: "created",
+	ttl, err := parseTTL(req.TTL)
+	if err != nil {
+		r.l.Error(err, "http - v1 - create")
+		errorResponse(c, 400, "invalid ttl")
+
+		return
+	}
+
+	job := entity.NewGenericJob(uuid.New().String(), "inference job")
+
+	if !registerCallback(c, r.w, r.l, job, entity.JobKindInference, req.callbackRequest) {
+		return
 	}
 
-	entryPoint, err := r.u.CreateJob(job)
+	err = r.u.CreateJob(job, req.spec(), ttl)
+	if err != nil {
+		unregisterCallback(r.w, r.l, job, req.callbackRequest)
+	}
+	if errors.Is(err, entity.ErrInvalidJobSpec) {
+		r.l.Error(err, "http - v1 - create")
+		errorResponse(c, 400, "invalid job spec")
+
+		return
+	}
 	if err != nil {
 		r.l.Error(err, "http - v1 - create")
 		errorResponse(c, 500, "database problems")
 		return
 	}
 
-	go func() {
-		time.Sleep(30 * time.Minute)
-		err := r.u.DeleteJob(job.ID)
-		if err != nil {
-			r.l.Error(err, "http - v1 - create")
-		}
-	}()
-
-	c.JSON(200, createInferenceJobResponse{JobId: job.ID, EntryPoint: entryPoint})
+	c.JSON(202, createInferenceJobResponse{JobId: job.ID})
 }
 
 type getInferenceJobResponse struct {
 	Job entity.GenericJob `json:"job"`
+	// EntryPoint and ExpiresAt are set once the job is running.
+	EntryPoint string    `json:"entryPoint,omitempty" example:"203.0.113.1:50002"`
+	ExpiresAt  time.Time `json:"expiresAt" example:"2023-11-01T12:30:00Z"`
+	// Attempts lists the failed attempts to provision the job, the reason
+	// of the final failure is the failureReason of the job.
+	Attempts []entity.ProvisioningAttempt `json:"attempts"`
+	// ProxyRequests counts the requests proxied to the job since the
+	// server started.
+	ProxyRequests uint64 `json:"proxyRequests" example:"42"`
 }
 
 // @Summary     get inference job
@@ -104,7 +180,13 @@ func (r *InferenceJobController) get(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, getInferenceJobResponse{job})
+	c.JSON(200, getInferenceJobResponse{
+		Job:           job.Job,
+		EntryPoint:    job.EntryPoint,
+		ExpiresAt:     job.ExpiresAt,
+		Attempts:      job.Attempts,
+		ProxyRequests: job.ProxyRequests,
+	})
 }
 
 type listInferenceJobResponse struct {
@@ -154,3 +236,83 @@ func (r *InferenceJobController) delete(c *gin.Context) {
 
 	successResponse(c, 200, "job deleted")
 }
+
+// @Summary     list inference job callback deliveries
+// @Description list the calls of the callback of an inference job, one delivery per terminal transition
+// @Description with every attempt to deliver it
+// @Tags  	    inference-jobs
+// @Produce     json
+// @Param       id   path      string  true  "Job ID"
+// @Success     200 {object} getDeliveriesResponse
+// @Failure     404 {object} eResponse
+// @Failure     500 {object} eResponse
+// @Router      /inference-jobs/{id}/deliveries [get]
+func (r *InferenceJobController) deliveries(c *gin.Context) {
+	deliveries(c, r.w, r.l)
+}
+
+type renewInferenceJobRequest struct {
+	// TTL extends the lease from now, like 30m, capped by the configured
+	// maximum. The job is renewed by its own ttl if left empty.
+	TTL string `json:"ttl" example:"30m"`
+}
+
+type renewInferenceJobResponse struct {
+	ExpiresAt time.Time `json:"expiresAt" example:"2023-11-01T12:30:00Z"`
+}
+
+// @Summary     renew inference job
+// @Description extend the lease of an inference job before it expires
+// @Tags  	    inference-jobs
+// @Accept      json
+// @Produce     json
+// @Param       id   path      string  true  "Job ID"
+// @Param       req body renewInferenceJobRequest false "request"
+// @Success     200 {object} renewInferenceJobResponse
+// @Failure     400 {object} eResponse
+// @Failure     404 {object} eResponse
+// @Failure     409 {object} eResponse
+// @Failure     500 {object} eResponse
+// @Router      /inference-jobs/{id}/renew [post]
+func (r *InferenceJobController) renew(c *gin.Context) {
+	id := c.Param("id")
+
+	// The body is optional.
+	var req renewInferenceJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		r.l.Error(err, "http - v1 - renew")
+		errorResponse(c, 400, "invalid request")
+
+		return
+	}
+
+	ttl, err := parseTTL(req.TTL)
+	if err != nil {
+		r.l.Error(err, "http - v1 - renew")
+		errorResponse(c, 400, "invalid ttl")
+
+		return
+	}
+
+	expiresAt, err := r.u.RenewJob(id, ttl)
+	if errors.Is(err, usecase.ErrJobNotFound) {
+		r.l.Error(err, "http - v1 - renew")
+		errorResponse(c, 404, "job not found")
+
+		return
+	}
+	if errors.Is(err, usecase.ErrJobFinished) {
+		r.l.Error(err, "http - v1 - renew")
+		errorResponse(c, 409, "job already finished")
+
+		return
+	}
+	if err != nil {
+		r.l.Error(err, "http - v1 - renew")
+		errorResponse(c, 500, "database problems")
+
+		return
+	}
+
+	c.JSON(200, renewInferenceJobResponse{ExpiresAt: expiresAt})
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+var inferenceProxyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "inference_proxy_requests_total",
+	Help: "Requests proxied to inference jobs, by response code.",
+}, []string{"code"})
+
+// @Summary     proxy to inference job
+// @Description forward a request to the entry point of a running inference job,
+// @Description streamed responses and WebSocket upgrades are passed through
+// @Tags  	    inference-jobs
+// @Param       id   path      string  true  "Job ID"
+// @Param       path path      string  true  "Path on the entry point"
+// @Failure     404 {object} eResponse
+// @Failure     409 {object} eResponse
+// @Failure     500 {object} eResponse
+// @Failure     502 {object} eResponse
+// @Router      /inference-jobs/{id}/proxy/{path} [get]
+// @Router      /inference-jobs/{id}/proxy/{path} [post]
+func (r *InferenceJobController) proxy(c *gin.Context) {
+	id := c.Param("id")
+
+	job, err := r.u.GetJob(id)
+	if errors.Is(err, usecase.ErrJobNotFound) {
+		r.l.Error(err, "http - v1 - proxy")
+		errorResponse(c, 404, "job not found")
+
+		return
+	}
+	if err != nil {
+		r.l.Error(err, "http - v1 - proxy")
+		errorResponse(c, 500, "database problems")
+
+		return
+	}
+
+	if job.Job.Status != entity.JobStateRunning || job.EntryPoint == "" {
+		errorResponse(c, 409, "job is not running")
+
+		return
+	}
+
+	target := &url.URL{Scheme: "http", Host: job.EntryPoint}
+	path := c.Param("path")
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.URL.Path = path
+			pr.Out.URL.RawPath = ""
+			pr.SetXForwarded()
+		},
+		// Flush right away, token streams must not be buffered.
+		FlushInterval: -1,
+		ModifyResponse: func(resp *http.Response) error {
+			inferenceProxyRequests.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			r.l.Error(err, "http - v1 - proxy")
+			inferenceProxyRequests.WithLabelValues(strconv.Itoa(http.StatusBadGateway)).Inc()
+			errorResponse(c, 502, "inference job is unreachable")
+		},
+	}
+
+	r.u.CountProxyRequest(id)
+
+	// Streams and upgraded connections outlive the timeouts of the server.
+	disableTimeouts(c)
+
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// disableWriteTimeout lifts the write timeout of the http server for a long
+// lived streaming response.
+func disableWriteTimeout(c *gin.Context) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
+
+// disableTimeouts lifts the read and write timeouts of the http server for a
+// long lived exchange, like a proxied stream or an upgraded connection. A
+// hijacked connection keeps the deadlines cleared here.
+func disableTimeouts(c *gin.Context) {
+	rc := http.NewResponseController(c.Writer)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+}
+
+// wantsEventStream reports whether the client asked for Server-Sent Events.
+func wantsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// queryBool parses an optional boolean query parameter, invalid values count
+// as false.
+func queryBool(c *gin.Context, key string) bool {
+	v, _ := strconv.ParseBool(c.Query(key))
+	return v
+}
@@ -1,6 +1,12 @@
 package v1
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/go-units"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
@@ -11,23 +17,87 @@ import (
 
 type TrainingJobController struct {
 	u usecase.TrainingJobRequester
+	w usecase.WebhookRequester
 	l logger.Interface
 }
 
-func InitTrainingJobRoutes(handler *gin.RouterGroup, u usecase.TrainingJobRequester, l logger.Interface) {
-	r := &TrainingJobController{u, l}
+func InitTrainingJobRoutes(handler *gin.RouterGroup, u usecase.TrainingJobRequester, w usecase.WebhookRequester, l logger.Interface) {
+	r := &TrainingJobController{u, w, l}
 
 	h := handler.Group("/training-jobs")
 	{
 		h.GET("/all", r.list)
 		h.POST("/create", r.create)
 		h.GET(":id", r.get)
+		h.GET(":id/logs", r.logs)
+		h.GET(":id/deliveries", r.deliveries)
+		h.DELETE(":id", r.delete)
 	}
 }
 
 type createTrainingJobRequest struct {
+	// TwccJobId runs a twcc job created beforehand, without it a twcc job is
+	// created from the spec if the job is placed on twcc.
 	TwccJobId       string `json:"twccJobId" example:"237139"`
 	DockerImageName string `json:"dockerImageName" example:"yjack0000cs12/llm-training:latest"`
+	// Command replaces the entrypoint of the image and Args its arguments.
+	Command    []string          `json:"command" example:"python"`
+	Args       []string          `json:"args" example:"train.py,--epochs=3"`
+	Env        map[string]string `json:"env"`
+	Mounts     []entity.Mount    `json:"mounts"`
+	WorkingDir string            `json:"workingDir" example:"/workspace"`
+	CPUs       float64           `json:"cpus" example:"2"`
+	// Memory and ShmSize accept sizes like 512m or 4g.
+	Memory  string            `json:"memory" example:"4g"`
+	ShmSize string            `json:"shmSize" example:"1g"`
+	Labels  map[string]string `json:"labels"`
+	// Flavor and Project override the configured defaults on twcc.
+	Flavor  string `json:"flavor" example:"1 GPU + 04 cores + 090GB memory"`
+	Project string `json:"project" example:"65662"`
+	// RegistryCredential names the configured credentials to pull the image
+	// with, by default they are picked by the registry of the image.
+	RegistryCredential string `json:"registryCredential" example:"ghcr"`
+	// PriorityClass is one of low, normal, high or urgent, defaults to normal.
+	PriorityClass string `json:"priorityClass" example:"normal" enums:"low,normal,high,urgent"`
+	// PlacementPolicy overrides the configured placement policy.
+	PlacementPolicy string `json:"placementPolicy" example:"local-first" enums:"local-first,remote-only,local-only,round-robin,least-loaded"`
+
+	callbackRequest
+}
+
+// spec turns the request into the spec of the job.
+func (req createTrainingJobRequest) spec() (entity.JobSpec, error) {
+	spec := entity.JobSpec{
+		Image:      req.DockerImageName,
+		Command:    req.Command,
+		Args:       req.Args,
+		Env:        req.Env,
+		Mounts:     req.Mounts,
+		WorkingDir: req.WorkingDir,
+		CPUs:       req.CPUs,
+		Labels:     req.Labels,
+		Flavor:     req.Flavor,
+		Project:    req.Project,
+
+		RegistryCredential: req.RegistryCredential,
+	}
+
+	var err error
+	if req.Memory != "" {
+		spec.Memory, err = units.RAMInBytes(req.Memory)
+		if err != nil {
+			return entity.JobSpec{}, fmt.Errorf("%w: memory: %v", entity.ErrInvalidJobSpec, err)
+		}
+	}
+
+	if req.ShmSize != "" {
+		spec.ShmSize, err = units.RAMInBytes(req.ShmSize)
+		if err != nil {
+			return entity.JobSpec{}, fmt.Errorf("%w: shm size: %v", entity.ErrInvalidJobSpec, err)
+		}
+	}
+
+	return spec, nil
 }
 
 type createTrainingJobResponse struct {
@@ -40,6 +110,7 @@ type createTrainingJobResponse struct {
 // @Accept      json
 // @Produce     json
 // @Success     200 {object} createTrainingJobResponse
+// @Failure     400 {object} eResponse
 // @Failure     500 {object} eResponse
 // @Router      /training-jobs/create [post]
 // @Param       req body createTrainingJobRequest true "request"
@@ -52,13 +123,45 @@ func (r *TrainingJobController) create(c *gin.Context) {
 		return
 	}
 
-	job := entity.GenericJob{
-		ID:     uuid.New().String(),
-		Name:   req.DockerImageName + "-" + req.TwccJobId,
-		Status: "created",
+	priority, err := entity.ParsePriorityClass(req.PriorityClass)
+	if err != nil {
+		r.l.Error(err, "http - v1 - create")
+		errorResponse(c, 400, "invalid priority class")
+
+		return
 	}
 
-	err := r.u.CreateJob(job, req.DockerImageName, req.TwccJobId)
+	spec, err := req.spec()
+	if err != nil {
+		r.l.Error(err, "http - v1 - create")
+		errorResponse(c, 400, "invalid job spec")
+
+		return
+	}
+
+	job := entity.NewGenericJob(uuid.New().String(), req.DockerImageName+"-"+req.TwccJobId)
+	job.Priority = priority
+
+	if !registerCallback(c, r.w, r.l, job, entity.JobKindTraining, req.callbackRequest) {
+		return
+	}
+
+	err = r.u.CreateJob(job, spec, req.TwccJobId, req.PlacementPolicy)
+	if err != nil {
+		unregisterCallback(r.w, r.l, job, req.callbackRequest)
+	}
+	if errors.Is(err, entity.ErrInvalidJobSpec) {
+		r.l.Error(err, "http - v1 - create")
+		errorResponse(c, 400, "invalid job spec")
+
+		return
+	}
+	if errors.Is(err, usecase.ErrUnknownPlacementPolicy) {
+		r.l.Error(err, "http - v1 - create")
+		errorResponse(c, 400, "invalid placement policy")
+
+		return
+	}
 	if err != nil {
 		r.l.Error(err, "http - v1 - create")
 		errorResponse(c, 500, "database problems")
@@ -119,3 +222,126 @@ func (r *TrainingJobController) list(c *gin.Context) {
 
 	c.JSON(200, listTrainingJobResponse{jobs})
 }
+
+// @Summary     stream training job logs
+// @Description stream the container logs of a docker training job as chunked plain text,
+// @Description or as Server-Sent Events with one "log" event per line if text/event-stream is accepted
+// @Tags  	    training-jobs
+// @Produce     plain
+// @Produce     text/event-stream
+// @Param       id          path   string  true   "Job ID"
+// @Param       follow      query  bool    false  "keep streaming new log lines"
+// @Param       since       query  string  false  "timestamp or relative duration like 10m"
+// @Param       tail        query  string  false  "number of lines from the end, or all"
+// @Param       timestamps  query  bool    false  "prefix every line with its timestamp"
+// @Success     200 {string} string
+// @Failure     404 {object} eResponse
+// @Failure     409 {object} eResponse
+// @Failure     500 {object} eResponse
+// @Router      /training-jobs/{id}/logs [get]
+func (r *TrainingJobController) logs(c *gin.Context) {
+	id := c.Param("id")
+
+	logs, err := r.u.GetJobLogs(c.Request.Context(), id, usecase.JobLogOptions{
+		Follow:     queryBool(c, "follow"),
+		Since:      c.Query("since"),
+		Tail:       c.DefaultQuery("tail", "all"),
+		Timestamps: queryBool(c, "timestamps"),
+	})
+	if errors.Is(err, usecase.ErrJobNotFound) {
+		r.l.Error(err, "http - v1 - logs")
+		errorResponse(c, 404, "job not found")
+
+		return
+	}
+	if errors.Is(err, usecase.ErrLogsUnavailable) {
+		r.l.Error(err, "http - v1 - logs")
+		errorResponse(c, 409, "logs are only available for docker jobs")
+
+		return
+	}
+	if err != nil {
+		r.l.Error(err, "http - v1 - logs")
+		errorResponse(c, 500, "Internal problems, please try again later")
+
+		return
+	}
+	defer logs.Close()
+
+	disableWriteTimeout(c)
+
+	if wantsEventStream(c) {
+		scanner := bufio.NewScanner(logs)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			c.SSEvent("log", scanner.Text())
+			c.Writer.Flush()
+		}
+
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(200)
+
+	buf := make([]byte, 32*1024)
+	c.Stream(func(w io.Writer) bool {
+		n, err := logs.Read(buf)
+		if n > 0 {
+			_, _ = w.Write(buf[:n])
+		}
+
+		return err == nil
+	})
+}
+
+// @Summary     list training job callback deliveries
+// @Description list the calls of the callback of a training job, one delivery per terminal transition
+// @Description with every attempt to deliver it
+// @Tags  	    training-jobs
+// @Produce     json
+// @Param       id   path      string  true  "Job ID"
+// @Success     200 {object} getDeliveriesResponse
+// @Failure     404 {object} eResponse
+// @Failure     500 {object} eResponse
+// @Router      /training-jobs/{id}/deliveries [get]
+func (r *TrainingJobController) deliveries(c *gin.Context) {
+	deliveries(c, r.w, r.l)
+}
+
+// @Summary     cancel training job
+// @Description cancel a queued or running training job, its container or twcc job is stopped
+// @Tags  	    training-jobs
+// @Accept      json
+// @Produce     json
+// @Param       id   path      string  true  "Job ID"
+// @Success     200 {object} sResponse
+// @Failure     404 {object} eResponse
+// @Failure     409 {object} eResponse
+// @Failure     500 {object} eResponse
+// @Router      /training-jobs/{id} [delete]
+func (r *TrainingJobController) delete(c *gin.Context) {
+	id := c.Param("id")
+
+	err := r.u.DeleteJob(id)
+	if errors.Is(err, usecase.ErrJobNotFound) {
+		r.l.Error(err, "http - v1 - delete")
+		errorResponse(c, 404, "job not found")
+
+		return
+	}
+	if errors.Is(err, entity.ErrInvalidTransition) {
+		r.l.Error(err, "http - v1 - delete")
+		errorResponse(c, 409, "job already finished")
+
+		return
+	}
+	if err != nil {
+		r.l.Error(err, "http - v1 - delete")
+		errorResponse(c, 500, "Internal problems, please try again later")
+
+		return
+	}
+
+	successResponse(c, 200, "job cancelled")
+}
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/pkg/logger"
+)
+
+// callbackRequest asks to be called once a job reached a terminal state.
+// The final transition of the job is POSTed to Callback. With a
+// CallbackSecret the X-Webhook-Signature header carries sha256= and the hex
+// HMAC-SHA256 of the X-Webhook-Timestamp header, a dot and the body.
+type callbackRequest struct {
+	Callback       string `json:"callback" example:"https://ci.example.com/hooks/jobs"`
+	CallbackSecret string `json:"callbackSecret"`
+}
+
+// registerCallback registers the callback of a job if the request has one.
+// It writes an error response and returns false if that failed. Callbacks
+// are registered before the job is created, so a job that finishes right
+// away still calls back.
+func registerCallback(c *gin.Context, w usecase.WebhookRequester, l logger.Interface, job entity.GenericJob, kind entity.JobKind, req callbackRequest) bool {
+	if req.Callback == "" {
+		if req.CallbackSecret != "" {
+			errorResponse(c, 400, "callback secret without callback")
+
+			return false
+		}
+
+		return true
+	}
+
+	err := w.RegisterWebhook(entity.Webhook{JobID: job.ID, Kind: kind, URL: req.Callback, Secret: req.CallbackSecret})
+	if errors.Is(err, entity.ErrInvalidWebhook) {
+		l.Error(err, "http - v1 - create")
+		errorResponse(c, 400, "invalid callback")
+
+		return false
+	}
+	if err != nil {
+		l.Error(err, "http - v1 - create")
+		errorResponse(c, 500, "database problems")
+
+		return false
+	}
+
+	return true
+}
+
+// unregisterCallback drops the callback of a job that could not be created.
+func unregisterCallback(w usecase.WebhookRequester, l logger.Interface, job entity.GenericJob, req callbackRequest) {
+	if req.Callback == "" {
+		return
+	}
+
+	if err := w.UnregisterWebhook(job.ID); err != nil {
+		l.Error(err, "http - v1 - create")
+	}
+}
+
+type getDeliveriesResponse struct {
+	Callback   string                   `json:"callback" example:"https://ci.example.com/hooks/jobs"`
+	Deliveries []entity.WebhookDelivery `json:"deliveries"`
+}
+
+// deliveries writes the callback deliveries of a job.
+func deliveries(c *gin.Context, w usecase.WebhookRequester, l logger.Interface) {
+	webhook, deliveries, err := w.GetWebhookDeliveries(c.Param("id"))
+	if errors.Is(err, usecase.ErrWebhookNotFound) {
+		errorResponse(c, 404, "job has no callback")
+
+		return
+	}
+	if err != nil {
+		l.Error(err, "http - v1 - deliveries")
+		errorResponse(c, 500, "database problems")
+
+		return
+	}
+
+	c.JSON(200, getDeliveriesResponse{Callback: webhook.URL, Deliveries: deliveries})
+}
@@ -3,36 +3,72 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"golang_backend_template/internal/usecase/ports"
 )
 
 type DockerAdapter struct {
 	dockerClient *client.Client
+	// credentials maps the name of registry credentials to the credentials.
+	credentials map[string]RegistryCredential
+
+	mu sync.Mutex
+	// pulled holds the ids of the images pulled since the adapter was
+	// created, and of the images they replaced.
+	pulled map[string]struct{}
 }
 
-func NewDockerAdapter(dockerClient *client.Client) *DockerAdapter {
-	return &DockerAdapter{dockerClient: dockerClient}
+func NewDockerAdapter(dockerClient *client.Client, credentials map[string]RegistryCredential) *DockerAdapter {
+	return &DockerAdapter{dockerClient: dockerClient, credentials: credentials, pulled: make(map[string]struct{})}
 }
 
-func (r *DockerAdapter) pullImage(ctx context.Context, dockerImageName string) error {
-	_, err := r.dockerClient.ImagePull(ctx, dockerImageName, types.ImagePullOptions{})
-	if err != nil {
-		return fmt.Errorf("DockerAdapter - PullImage - r.dockerClient.ImagePull: %w", err)
+func (r *DockerAdapter) CreateContainer(ctx context.Context, spec ports.ContainerSpec) (string, error) {
+	mounts := make([]mount.Mount, 0, len(spec.Mounts))
+	for _, m := range spec.Mounts {
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.Type(m.Type),
+			Source:   m.Source,
+			Target:   m.Target,
+			ReadOnly: m.ReadOnly,
+		})
 	}
 
-	return nil
-}
+	labels := make(map[string]string, len(spec.Labels)+1)
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	if spec.JobID != "" {
+		labels[ports.JobIDLabel] = spec.JobID
+	}
 
-func (r *DockerAdapter) CreateContainer(ctx context.Context, dockerImageName string) (string, error) {
-	if err := r.pullImage(ctx, dockerImageName); err != nil {
-		return "", fmt.Errorf("DockerAdapter - CreateContainerJob - r.pullImage: %w", err)
+	config := &container.Config{
+		Image:      spec.Image,
+		Entrypoint: spec.Command,
+		Cmd:        spec.Args,
+		Env:        spec.Env,
+		WorkingDir: spec.WorkingDir,
+		Labels:     labels,
 	}
-	resp, err := r.dockerClient.ContainerCreate(ctx, &container.Config{
-		Image: dockerImageName,
-	}, nil, nil, nil, "")
+	hostConfig := &container.HostConfig{
+		Mounts:  mounts,
+		ShmSize: spec.ShmSize,
+		Resources: container.Resources{
+			NanoCPUs: int64(spec.CPUs * 1e9),
+			Memory:   spec.Memory,
+		},
+	}
+
+	resp, err := r.dockerClient.ContainerCreate(ctx, config, hostConfig, nil, nil, "")
 	if err != nil {
 		return "", fmt.Errorf("DockerAdapter - CreateContainerJob - r.dockerClient.ContainerCreate: %w", err)
 	}
@@ -40,19 +76,166 @@ func (r *DockerAdapter) CreateContainer(ctx context.Context, dockerImageName str
 	return resp.ID, nil
 }
 
-func (r *DockerAdapter) ContainerStartWithCallback(ctx context.Context, containerID string, callback func()) error {
+func (r *DockerAdapter) ContainerStartWithCallback(ctx context.Context, containerID string, callback func(ports.ContainerExit)) error {
 	if err := r.dockerClient.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
-		panic(err)
+		return fmt.Errorf("DockerAdapter - ContainerStartWithCallback - r.dockerClient.ContainerStart: %w", err)
 	}
+
+	return r.WaitContainer(ctx, containerID, callback)
+}
+
+func (r *DockerAdapter) WaitContainer(ctx context.Context, containerID string, callback func(ports.ContainerExit)) error {
 	statusCh, errCh := r.dockerClient.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
 	select {
 	case err := <-errCh:
 		if err != nil {
-			return fmt.Errorf("DockerAdapter - ContainerFinishedCallback - r.dockerClient.ContainerWait: %w", err)
+			return fmt.Errorf("DockerAdapter - WaitContainer - r.dockerClient.ContainerWait: %w", err)
+		}
+	case status := <-statusCh:
+		exit := ports.ContainerExit{ExitCode: status.StatusCode}
+		if status.Error != nil {
+			exit.Error = status.Error.Message
+		}
+
+		// The wait response lacks the OOM flag, the container state has it.
+		if info, err := r.dockerClient.ContainerInspect(ctx, containerID); err == nil && info.State != nil {
+			exit.OOMKilled = info.State.OOMKilled
+			if exit.Error == "" {
+				exit.Error = info.State.Error
+			}
+		}
+
+		callback(exit)
+	}
+
+	return nil
+}
+
+func (r *DockerAdapter) ListContainers(ctx context.Context) ([]ports.ContainerState, error) {
+	containers, err := r.dockerClient.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", ports.JobIDLabel)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DockerAdapter - ListContainers - r.dockerClient.ContainerList: %w", err)
+	}
+
+	states := make([]ports.ContainerState, 0, len(containers))
+	for _, c := range containers {
+		state := ports.ContainerState{ID: c.ID, JobID: c.Labels[ports.JobIDLabel], State: c.State}
+
+		// The list lacks how a container exited, the container state has it.
+		if c.State == "exited" || c.State == "dead" {
+			info, err := r.dockerClient.ContainerInspect(ctx, c.ID)
+			if err != nil {
+				return nil, fmt.Errorf("DockerAdapter - ListContainers - r.dockerClient.ContainerInspect: %w", err)
+			}
+
+			setExit(&state, info)
+		}
+
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+func (r *DockerAdapter) InspectContainer(ctx context.Context, containerID string) (ports.ContainerState, error) {
+	info, err := r.dockerClient.ContainerInspect(ctx, containerID)
+	if client.IsErrNotFound(err) {
+		return ports.ContainerState{}, fmt.Errorf("DockerAdapter - InspectContainer - %w: %s", ports.ErrContainerNotFound, containerID)
+	}
+	if err != nil {
+		return ports.ContainerState{}, fmt.Errorf("DockerAdapter - InspectContainer - r.dockerClient.ContainerInspect: %w", err)
+	}
+
+	state := ports.ContainerState{ID: info.ID}
+	if info.Config != nil {
+		state.JobID = info.Config.Labels[ports.JobIDLabel]
+	}
+	if info.State != nil {
+		state.State = info.State.Status
+		if state.State == "exited" || state.State == "dead" {
+			setExit(&state, info)
 		}
-	case <-statusCh:
-		callback()
+	}
+
+	return state, nil
+}
+
+// setExit sets how the container of info exited.
+func setExit(state *ports.ContainerState, info types.ContainerJSON) {
+	if info.State == nil {
+		return
+	}
+
+	state.Exit = ports.ContainerExit{
+		ExitCode:  int64(info.State.ExitCode),
+		OOMKilled: info.State.OOMKilled,
+		Error:     info.State.Error,
+	}
+	state.FinishedAt, _ = time.Parse(time.RFC3339Nano, info.State.FinishedAt)
+}
+
+func (r *DockerAdapter) StopContainer(ctx context.Context, containerID string) error {
+	if err := r.dockerClient.ContainerStop(ctx, containerID, container.StopOptions{}); err != nil {
+		return fmt.Errorf("DockerAdapter - StopContainer - r.dockerClient.ContainerStop: %w", err)
+	}
+
+	return nil
+}
+
+func (r *DockerAdapter) RemoveContainer(ctx context.Context, containerID string) error {
+	err := r.dockerClient.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
+	if err != nil {
+		return fmt.Errorf("DockerAdapter - RemoveContainer - r.dockerClient.ContainerRemove: %w", err)
 	}
 
 	return nil
 }
+
+func (r *DockerAdapter) ContainerLogs(ctx context.Context, containerID string, opts ports.ContainerLogOptions) (io.ReadCloser, error) {
+	info, err := r.dockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("DockerAdapter - ContainerLogs - r.dockerClient.ContainerInspect: %w", err)
+	}
+
+	logs, err := r.dockerClient.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Since:      opts.Since,
+		Tail:       opts.Tail,
+		Timestamps: opts.Timestamps,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DockerAdapter - ContainerLogs - r.dockerClient.ContainerLogs: %w", err)
+	}
+
+	// Logs of a container with a TTY are a raw stream, otherwise stdout and
+	// stderr are multiplexed into frames that have to be split again.
+	if info.Config != nil && info.Config.Tty {
+		return logs, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(pw, pw, logs)
+		logs.Close()
+		pw.CloseWithError(err)
+	}()
+
+	return &demuxedLogs{PipeReader: pr, logs: logs}, nil
+}
+
+// demuxedLogs closes the docker log stream along with the pipe, which stops
+// the demultiplexing goroutine of a followed stream.
+type demuxedLogs struct {
+	*io.PipeReader
+	logs io.Closer
+}
+
+func (d *demuxedLogs) Close() error {
+	d.logs.Close()
+	return d.PipeReader.Close()
+}
@@ -0,0 +1,243 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/pkg/jsonmessage"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// _defaultRegistry is the registry of images without a registry host.
+const _defaultRegistry = "docker.io"
+
+// RegistryCredential authenticates image pulls from Registry, either with a
+// username and password or with an identity token.
+type RegistryCredential struct {
+	Registry      string
+	Username      string
+	Password      string
+	IdentityToken string
+}
+
+// registryHost normalizes the address of a registry to its host.
+func registryHost(address string) string {
+	address = strings.TrimPrefix(address, "https://")
+	address = strings.TrimPrefix(address, "http://")
+	address, _, _ = strings.Cut(address, "/")
+
+	switch address {
+	case "", "index.docker.io", "registry-1.docker.io":
+		return _defaultRegistry
+	}
+
+	return address
+}
+
+// imageRegistry returns the registry host of an image reference. Like docker,
+// the first path component is a host only if it looks like one.
+func imageRegistry(image string) string {
+	host, _, ok := strings.Cut(image, "/")
+	if !ok || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		return _defaultRegistry
+	}
+
+	return registryHost(host)
+}
+
+// registryAuth encodes the credentials used to pull image. Images without
+// matching credentials are pulled anonymously.
+func (r *DockerAdapter) registryAuth(image string, credential string) (string, error) {
+	var (
+		cred RegistryCredential
+		ok   bool
+	)
+
+	if credential != "" {
+		cred, ok = r.credentials[credential]
+		if !ok {
+			return "", fmt.Errorf("unknown registry credential %q", credential)
+		}
+	} else {
+		host := imageRegistry(image)
+		for _, c := range r.credentials {
+			if registryHost(c.Registry) == host {
+				cred, ok = c, true
+				break
+			}
+		}
+
+		if !ok {
+			return "", nil
+		}
+	}
+
+	return registry.EncodeAuthConfig(registry.AuthConfig{
+		Username:      cred.Username,
+		Password:      cred.Password,
+		IdentityToken: cred.IdentityToken,
+		ServerAddress: cred.Registry,
+	})
+}
+
+func (r *DockerAdapter) PullImage(ctx context.Context, image string, credential string, progress func(entity.ImagePullProgress)) error {
+	auth, err := r.registryAuth(image, credential)
+	if err != nil {
+		return fmt.Errorf("DockerAdapter - PullImage - r.registryAuth: %w", err)
+	}
+
+	// The image a pull replaces is left dangling, it is remembered along
+	// with the pulled one.
+	r.rememberImage(ctx, image)
+
+	stream, err := r.dockerClient.ImagePull(ctx, image, types.ImagePullOptions{RegistryAuth: auth})
+	if err != nil {
+		return fmt.Errorf("DockerAdapter - PullImage - r.dockerClient.ImagePull: %w", err)
+	}
+	defer stream.Close()
+
+	// The pull only finishes once the stream is drained, errors of the pull
+	// itself are reported inside of the stream.
+	layers := newPullLayers()
+	decoder := json.NewDecoder(stream)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				r.rememberImage(ctx, image)
+				return nil
+			}
+
+			return fmt.Errorf("DockerAdapter - PullImage - decoder.Decode: %w", err)
+		}
+
+		if msg.Error != nil {
+			return fmt.Errorf("DockerAdapter - PullImage - pull: %w", msg.Error)
+		}
+
+		if layers.update(msg) && progress != nil {
+			progress(layers.progress())
+		}
+	}
+}
+
+// rememberImage records the id of a local image as pulled, images that do
+// not exist locally are skipped.
+func (r *DockerAdapter) rememberImage(ctx context.Context, image string) {
+	info, _, err := r.dockerClient.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pulled[info.ID] = struct{}{}
+}
+
+func (r *DockerAdapter) ListDanglingImages(ctx context.Context) ([]string, error) {
+	images, err := r.dockerClient.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("dangling", "true")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DockerAdapter - ListDanglingImages - r.dockerClient.ImageList: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(images))
+	for _, image := range images {
+		if _, ok := r.pulled[image.ID]; ok {
+			ids = append(ids, image.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+func (r *DockerAdapter) RemoveImage(ctx context.Context, imageID string) error {
+	if _, err := r.dockerClient.ImageRemove(ctx, imageID, types.ImageRemoveOptions{}); err != nil {
+		return fmt.Errorf("DockerAdapter - RemoveImage - r.dockerClient.ImageRemove: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pulled, imageID)
+
+	return nil
+}
+
+type pullLayer struct {
+	current int64
+	total   int64
+	done    bool
+}
+
+// pullLayers tracks the layers of a pull by the status messages of docker.
+type pullLayers struct {
+	order []string
+	byID  map[string]*pullLayer
+}
+
+func newPullLayers() *pullLayers {
+	return &pullLayers{byID: make(map[string]*pullLayer)}
+}
+
+// update applies a status message and reports whether it changed a layer.
+// Messages about the image itself, like the final digest, are ignored.
+func (p *pullLayers) update(msg jsonmessage.JSONMessage) bool {
+	if msg.ID == "" {
+		return false
+	}
+
+	layer, ok := p.byID[msg.ID]
+	if !ok {
+		switch msg.Status {
+		case "Pulling fs layer", "Waiting", "Already exists":
+		default:
+			return false
+		}
+
+		layer = &pullLayer{}
+		p.byID[msg.ID] = layer
+		p.order = append(p.order, msg.ID)
+	}
+
+	switch msg.Status {
+	case "Downloading":
+		if msg.Progress != nil {
+			layer.current = msg.Progress.Current
+			layer.total = msg.Progress.Total
+		}
+	case "Download complete":
+		layer.current = layer.total
+	case "Pull complete", "Already exists":
+		layer.current = layer.total
+		layer.done = true
+	}
+
+	return true
+}
+
+func (p *pullLayers) progress() entity.ImagePullProgress {
+	progress := entity.ImagePullProgress{Layers: len(p.order)}
+	for _, id := range p.order {
+		layer := p.byID[id]
+		if layer.done {
+			progress.LayersDone++
+		}
+
+		progress.Current += layer.current
+		progress.Total += layer.total
+	}
+
+	return progress
+}
@@ -0,0 +1,115 @@
+package adapter
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryConfig configures retries of failed TWCC requests. The delay before
+// retry n is picked at random up to BaseDelay*2^n, capped at MaxDelay.
+type RetryConfig struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// retryTransport retries requests that failed transiently. Rate limited and
+// unavailable responses are retried for every method, since the request was
+// not processed. Connection errors, internal server errors and bad gateway
+// or gateway timeout responses are only retried for idempotent methods, a
+// retried submit could start a job twice.
+type retryTransport struct {
+	base   http.RoundTripper
+	config RetryConfig
+}
+
+func newRetryTransport(base http.RoundTripper, config RetryConfig) *retryTransport {
+	return &retryTransport{base: base, config: config}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func (t *retryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	// Only a body that can be read again can be sent again.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return isIdempotent(req.Method) && req.Context().Err() == nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		// The gateway may have passed the request on before it failed.
+		return isIdempotent(req.Method)
+	}
+
+	return false
+}
+
+// delay returns how long to wait before the given retry, a Retry-After header
+// of the failed response takes precedence.
+func (t *retryTransport) delay(retry int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, t.config.MaxDelay)
+		}
+	}
+
+	backoff := t.config.MaxDelay
+	if retry < 32 {
+		backoff = min(t.config.BaseDelay<<retry, t.config.MaxDelay)
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		attempt := req
+		if retry > 0 && req.Body != nil && req.Body != http.NoBody {
+			// The body of the previous attempt has been consumed.
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			attempt = req.Clone(req.Context())
+			attempt.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attempt)
+		if retry >= t.config.MaxRetries || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.delay(retry, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
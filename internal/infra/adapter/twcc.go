@@ -5,55 +5,227 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"golang_backend_template/internal/usecase/ports"
 )
 
+// TwccConfig points the adapter at a TWCC API gateway. Requests go to
+// BaseURL/api/APIVersion/APIHost/..., APIHost is also sent as X-API-HOST.
+type TwccConfig struct {
+	BaseURL    string
+	APIHost    string
+	APIVersion string
+	APIKey     string
+	// Project and JobFlavor are used for twcc jobs and CCS that do not
+	// pick their own.
+	Project   string
+	JobFlavor string
+	// Timeout bounds a call including its retries.
+	Timeout time.Duration
+	Retry   RetryConfig
+}
+
 type TwccAdapter struct {
-	client     http.Client
-	twccAPIKey string
+	client http.Client
+	config TwccConfig
 }
 
-func NewTwccAdapter(twccAPIKey string) *TwccAdapter {
+func NewTwccAdapter(config TwccConfig) *TwccAdapter {
 	client := http.Client{
-		Timeout: 5 * time.Second,
+		Timeout:   config.Timeout,
+		Transport: newRetryTransport(http.DefaultTransport, config.Retry),
 	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
 	return &TwccAdapter{
-		client:     client,
-		twccAPIKey: twccAPIKey,
+		client: client,
+		config: config,
 	}
 }
 
-func (r *TwccAdapter) newClient(method string, requestURL string, body io.Reader) *http.Request {
+// url builds the URL of an API path, like /jobs/12345/, with the configured
+// API version.
+func (r *TwccAdapter) url(format string, a ...any) string {
+	return r.versionedURL(r.config.APIVersion, format, a...)
+}
+
+func (r *TwccAdapter) versionedURL(version string, format string, a ...any) string {
+	return r.config.BaseURL + "/api/" + version + "/" + r.config.APIHost + fmt.Sprintf(format, a...)
+}
+
+// newRequest builds an authenticated request to the gateway, it fails for
+// a malformed URL, e.g. one built from an invalid id.
+func (r *TwccAdapter) newRequest(method string, requestURL string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequest(method, requestURL, body)
 	if err != nil {
-		fmt.Printf("client: could not create request: %s\n", err)
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "TWCC-CLI")
-	req.Header.Set("X-API-HOST", "k8s-D-twcc")
-	req.Header.Set("x-API-KEY", r.twccAPIKey)
+	req.Header.Set("X-API-HOST", r.config.APIHost)
+	req.Header.Set("x-API-KEY", r.config.APIKey)
 
-	return req
+	return req, nil
 }
 
-func (r *TwccAdapter) RunTwccJob(twccJobId string) error {
-	requestURL := fmt.Sprintf("https://apigateway.twcc.ai/api/v3/k8s-D-twcc/jobs/%s/submit/", twccJobId)
-	req := r.newClient("POST", requestURL, nil)
-	resp, err := r.client.Do(req)
+// twccErrorBody is how the gateway describes errors, depending on the API
+// the message is in detail, message or error.
+type twccErrorBody struct {
+	Detail  string `json:"detail"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
 
+// twccError turns an error response into a *ports.TwccError.
+func twccError(statusCode int, body []byte) error {
+	var b twccErrorBody
+	detail := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &b); err == nil {
+		switch {
+		case b.Detail != "":
+			detail = b.Detail
+		case b.Message != "":
+			detail = b.Message
+		case b.Error != "":
+			detail = b.Error
+		}
+	}
+
+	// TWCC reports an exceeded quota as a client error, with the reason only
+	// in the detail. Other statuses keep their kind whatever their detail
+	// says, a gateway timeout "exceeded" is still worth a retry.
+	kind := ports.ErrTwccPermanent
+	lower := strings.ToLower(detail)
+	isQuota := strings.Contains(lower, "quota") || strings.Contains(lower, "exceed")
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		kind = ports.ErrTwccRateLimited
+	case statusCode == http.StatusRequestTimeout || statusCode >= 500:
+		kind = ports.ErrTwccTransient
+	case statusCode == http.StatusNotFound:
+		kind = ports.ErrTwccNotFound
+	case statusCode == http.StatusUnauthorized:
+		kind = ports.ErrTwccUnauthorized
+	case isQuota && (statusCode == http.StatusBadRequest || statusCode == http.StatusForbidden ||
+		statusCode == http.StatusUnprocessableEntity):
+		kind = ports.ErrTwccQuotaExceeded
+	case statusCode == http.StatusForbidden:
+		kind = ports.ErrTwccUnauthorized
+	}
+
+	return &ports.TwccError{Kind: kind, StatusCode: statusCode, Detail: detail}
+}
+
+// do sends a request and returns the body of a successful response. Failed
+// connections are transient, error responses are parsed into a
+// *ports.TwccError.
+func (r *TwccAdapter) do(req *http.Request) ([]byte, error) {
+	resp, err := r.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("TwccAdapter - RunTwccJob - client.Do: %w", err)
+		return nil, fmt.Errorf("%w: %w", ports.ErrTwccTransient, err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("TwccAdapter - RunTwccJob - ioutil.ReadAll: %w", err)
+		return nil, fmt.Errorf("%w: %w", ports.ErrTwccTransient, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, twccError(resp.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+type twccJobMount struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+type createTwccJobRequest struct {
+	Name    string         `json:"name"`
+	Project string         `json:"project"`
+	Image   string         `json:"image"`
+	Flavor  string         `json:"flavor"`
+	Command string         `json:"cmd"`
+	Env     []string       `json:"env,omitempty"`
+	Mounts  []twccJobMount `json:"mounts,omitempty"`
+}
+
+func (r *TwccAdapter) CreateTwccJob(spec ports.TwccJobSpec) (string, error) {
+	body := createTwccJobRequest{
+		Name:    spec.Name,
+		Project: spec.Project,
+		Image:   spec.Image,
+		Flavor:  spec.Flavor,
+		Command: shellJoin(spec.Command),
+		Env:     spec.Env,
+	}
+	if body.Project == "" {
+		body.Project = r.config.Project
+	}
+	if body.Flavor == "" {
+		body.Flavor = r.config.JobFlavor
+	}
+	for _, m := range spec.Mounts {
+		body.Mounts = append(body.Mounts, twccJobMount(m))
+	}
+
+	if body.Project == "" {
+		return "", fmt.Errorf("TwccAdapter - CreateTwccJob - no project configured")
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("TwccAdapter - CreateTwccJob - json.Marshal: %w", err)
+	}
+
+	req, err := r.newRequest("POST", r.url("/jobs/"), bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("TwccAdapter - CreateTwccJob - r.newRequest: %w", err)
+	}
+	respBody, err := r.do(req)
+	if err != nil {
+		return "", fmt.Errorf("TwccAdapter - CreateTwccJob - r.do: %w", err)
+	}
+
+	var job TwccJobResponse
+	if err := json.Unmarshal(respBody, &job); err != nil {
+		return "", fmt.Errorf("TwccAdapter - CreateTwccJob - json.Unmarshal: %w", err)
+	}
+
+	return fmt.Sprint(job.ID), nil
+}
+
+// shellJoin quotes a command for the shell twcc runs it with.
+func shellJoin(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`|&;<>()*?[]{}~#") {
+			quoted = append(quoted, arg)
+			continue
+		}
+
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
 	}
 
-	if resp.StatusCode != http.StatusCreated || string(body) != "" {
-		return fmt.Errorf("TwccAdapter - RunTwccJob - resp.StatusCode: %w - Create fail ", err)
+	return strings.Join(quoted, " ")
+}
+
+func (r *TwccAdapter) RunTwccJob(twccJobId string) error {
+	requestURL := r.url("/jobs/%s/submit/", twccJobId)
+	req, err := r.newRequest("POST", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("TwccAdapter - RunTwccJob - r.newRequest: %w", err)
+	}
+	if _, err := r.do(req); err != nil {
+		return fmt.Errorf("TwccAdapter - RunTwccJob - r.do: %w", err)
 	}
 
 	return nil
@@ -71,33 +243,38 @@ type TwccJobResponse struct {
 }
 
 func (r *TwccAdapter) GetTwccJobStatus(twccJobId string) (string, error) {
-	requestURL := fmt.Sprintf("https://apigateway.twcc.ai/api/v3/k8s-D-twcc/jobs/%s/", twccJobId)
-	req := r.newClient("GET", requestURL, nil)
-	resp, err := r.client.Do(req)
-
+	requestURL := r.url("/jobs/%s/", twccJobId)
+	req, err := r.newRequest("GET", requestURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("TwccAdapter - RunTwccJob - client.Do: %w", err)
+		return "", fmt.Errorf("TwccAdapter - GetTwccJobStatus - r.newRequest: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := r.do(req)
 	if err != nil {
-		return "", fmt.Errorf("TwccAdapter - RunTwccJob - ioutil.ReadAll: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK || string(body) == "" {
-		return "", fmt.Errorf("TwccAdapter - RunTwccJob - resp.StatusCode: %w", err)
+		return "", fmt.Errorf("TwccAdapter - GetTwccJobStatus - r.do: %w", err)
 	}
 
 	var job TwccJobResponse
 	if err := json.Unmarshal(body, &job); err != nil {
-		return "", err
+		return "", fmt.Errorf("TwccAdapter - GetTwccJobStatus - json.Unmarshal: %w", err)
 	}
 
 	// Return the status field
 	return job.Status, nil
 }
 
+func (r *TwccAdapter) CancelTwccJob(twccJobId string) error {
+	requestURL := r.url("/jobs/%s/cancel/", twccJobId)
+	req, err := r.newRequest("POST", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("TwccAdapter - CancelTwccJob - r.newRequest: %w", err)
+	}
+	if _, err := r.do(req); err != nil {
+		return fmt.Errorf("TwccAdapter - CancelTwccJob - r.do: %w", err)
+	}
+
+	return nil
+}
+
 type CreateTwccCCSResponse struct {
 	ID     int    `json:"id"`
 	Name   string `json:"name"`
@@ -105,44 +282,152 @@ type CreateTwccCCSResponse struct {
 	// ... include other fields if necessary
 }
 
-func (r *TwccAdapter) CreateTwccCCS() (string, error) {
-	requestURL := "https://apigateway.twcc.ai/api/v2/k8s-D-twcc/sites/"
-	body := bytes.NewBuffer([]byte(`{
-		"name": "inference-service",
-		"desc": "inference-service created GPU container",
-		"project": 65662,
-		"solution": 4
-	}`))
-	req := r.newClient("POST", requestURL, body)
-	req.Header.Set("x-extra-property-flavor", "1 GPU + 04 cores + 090GB memory")
-	req.Header.Set("x-extra-property-image", "tensorflow-23.08-tf2-py3:latest")
-	req.Header.Set("x-extra-property-replica", "1")
-	req.Header.Set("x-extra-property-gpfs02-mount-path", "/home/yjack0000")
-	req.Header.Set("x-extra-property-gpfs01-mount-path", "/work/yjack0000")
-	resp, err := r.client.Do(req)
+// createTwccCCSRequest is the body of a CCS create request, the container
+// itself is described by the x-extra-property headers.
+type createTwccCCSRequest struct {
+	Name     string `json:"name"`
+	Desc     string `json:"desc"`
+	Project  int    `json:"project"`
+	Solution int    `json:"solution"`
+}
+
+// _twccCCSSolution is the solution id of GPU containers.
+const _twccCCSSolution = 4
+
+func (r *TwccAdapter) CreateTwccCCS(spec ports.TwccCCSSpec) (string, error) {
+	projectID := spec.Project
+	if projectID == "" {
+		projectID = r.config.Project
+	}
 
+	project, err := strconv.Atoi(projectID)
 	if err != nil {
-		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - client.Do: %w", err)
+		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - invalid project %q", projectID)
+	}
+
+	if spec.Image == "" || spec.Flavor == "" {
+		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - image and flavor are required")
+	}
+
+	// The description carries the job id or the pool spec, names are too
+	// short for them.
+	desc := spec.Name + " created GPU container"
+	switch {
+	case spec.JobID != "":
+		desc = ports.JobIDLabel + "=" + spec.JobID
+	case spec.PoolSpec != "":
+		desc = ports.PoolSpecLabel + "=" + spec.PoolSpec
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	b, err := json.Marshal(createTwccCCSRequest{
+		Name:     spec.Name,
+		Desc:     desc,
+		Project:  project,
+		Solution: _twccCCSSolution,
+	})
 	if err != nil {
-		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - ioutil.ReadAll: %w", err)
+		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - json.Marshal: %w", err)
+	}
+
+	// Sites are only created through the v2 API.
+	req, err := r.newRequest("POST", r.versionedURL("v2", "/sites/"), bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - r.newRequest: %w", err)
+	}
+	req.Header.Set("x-extra-property-flavor", spec.Flavor)
+	req.Header.Set("x-extra-property-image", spec.Image)
+	req.Header.Set("x-extra-property-replica", strconv.Itoa(max(spec.Replica, 1)))
+	for storage, path := range spec.Mounts {
+		req.Header.Set("x-extra-property-"+storage+"-mount-path", path)
 	}
 
-	if resp.StatusCode != http.StatusCreated || string(respBody) == "" {
-		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - resp.StatusCode: %d", resp.StatusCode)
+	respBody, err := r.do(req)
+	if err != nil {
+		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - r.do: %w", err)
 	}
 
 	var ccs CreateTwccCCSResponse
 	if err := json.Unmarshal(respBody, &ccs); err != nil {
-		return "", err
+		return "", fmt.Errorf("TwccAdapter - CreateTwccCCS - json.Unmarshal: %w", err)
 	}
 
 	return fmt.Sprint(ccs.ID), nil
 }
 
+func (r *TwccAdapter) GetTwccCCSStatus(twccCCSId string) (string, error) {
+	req, err := r.newRequest("GET", r.url("/sites/%s/", twccCCSId), nil)
+	if err != nil {
+		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - r.newRequest: %w", err)
+	}
+	body, err := r.do(req)
+	if err != nil {
+		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - r.do: %w", err)
+	}
+
+	var ccs CreateTwccCCSResponse
+	if err := json.Unmarshal(body, &ccs); err != nil {
+		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - json.Unmarshal: %w", err)
+	}
+
+	return ccs.Status, nil
+}
+
+// twccCCSListItem is a CCS in the site list of a project.
+type twccCCSListItem struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Desc   string `json:"desc"`
+	Status string `json:"status"`
+}
+
+func (r *TwccAdapter) ListTwccCCS(projectID string) ([]ports.TwccCCS, error) {
+	if projectID == "" {
+		projectID = r.config.Project
+	}
+
+	project, err := strconv.Atoi(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("TwccAdapter - ListTwccCCS - invalid project %q", projectID)
+	}
+
+	req, err := r.newRequest("GET", r.url("/sites/?project=%d", project), nil)
+	if err != nil {
+		return nil, fmt.Errorf("TwccAdapter - ListTwccCCS - r.newRequest: %w", err)
+	}
+	body, err := r.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("TwccAdapter - ListTwccCCS - r.do: %w", err)
+	}
+
+	var items []twccCCSListItem
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, fmt.Errorf("TwccAdapter - ListTwccCCS - json.Unmarshal: %w", err)
+	}
+
+	sites := make([]ports.TwccCCS, 0, len(items))
+	for _, item := range items {
+		jobID, ok := strings.CutPrefix(item.Desc, ports.JobIDLabel+"=")
+		if !ok {
+			jobID = ""
+		}
+
+		poolSpec, ok := strings.CutPrefix(item.Desc, ports.PoolSpecLabel+"=")
+		if !ok {
+			poolSpec = ""
+		}
+
+		sites = append(sites, ports.TwccCCS{
+			ID:       fmt.Sprint(item.ID),
+			Name:     item.Name,
+			JobID:    jobID,
+			PoolSpec: poolSpec,
+			Status:   item.Status,
+		})
+	}
+
+	return sites, nil
+}
+
 type GetTwccCCSStatusResponse struct {
 	Service []struct {
 		Name      string   `json:"name"`
@@ -165,115 +450,107 @@ type GetTwccCCSStatusResponse struct {
 	/// ... include other fields if necessary
 }
 
-func (r *TwccAdapter) GetTwccCCSEntryPoint(twccCCSId string) (string, error) {
-	requestURL := fmt.Sprintf("https://apigateway.twcc.ai/api/v3/k8s-D-twcc/sites/%s/container/", twccCCSId)
-	req := r.newClient("GET", requestURL, nil)
-	resp, err := r.client.Do(req)
-
+func (r *TwccAdapter) getTwccCCSContainer(twccCCSId string) (GetTwccCCSStatusResponse, error) {
+	requestURL := r.url("/sites/%s/container/", twccCCSId)
+	req, err := r.newRequest("GET", requestURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - client.Do: %w", err)
+		return GetTwccCCSStatusResponse{}, fmt.Errorf("TwccAdapter - getTwccCCSContainer - r.newRequest: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := r.do(req)
 	if err != nil {
-		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - ioutil.ReadAll: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK || string(body) == "" {
-		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - resp.StatusCode: %w", err)
+		return GetTwccCCSStatusResponse{}, err
 	}
 
 	var ccs GetTwccCCSStatusResponse
 	if err := json.Unmarshal(body, &ccs); err != nil {
-		return "", err
+		return GetTwccCCSStatusResponse{}, err
 	}
 
-	if len(ccs.Service) == 0 {
-		return "", fmt.Errorf("TwccAdapter - GetTwccCCSEntryPoint - len(ccs.Service) == 0")
+	return ccs, nil
+}
+
+func (r *TwccAdapter) GetTwccCCSEntryPoint(twccCCSId string, targetPort int) (string, error) {
+	ccs, err := r.getTwccCCSContainer(twccCCSId)
+	if err != nil {
+		return "", fmt.Errorf("TwccAdapter - GetTwccCCSEntryPoint - r.getTwccCCSContainer: %w", err)
 	}
 
-	// Return Public IP and Port concated
-	// Ports[2] Refer to the port of 5000
-	entryPoint := ccs.Service[0].PublicIP[0] + ":" + fmt.Sprint(ccs.Service[0].Ports[2].Port)
+	for _, service := range ccs.Service {
+		if len(service.PublicIP) == 0 {
+			continue
+		}
+
+		for _, port := range service.Ports {
+			if port.TargetPort == targetPort {
+				return net.JoinHostPort(service.PublicIP[0], strconv.Itoa(port.Port)), nil
+			}
+		}
+	}
 
-	return entryPoint, nil
+	return "", fmt.Errorf("TwccAdapter - GetTwccCCSEntryPoint - port %d has no public IP: %w",
+		targetPort, ports.ErrTwccCCSNotReady)
 }
 
 func (r *TwccAdapter) getTwccCCSPodName(twccCCSId string) (string, error) {
-	requestURL := fmt.Sprintf("https://apigateway.twcc.ai/api/v3/k8s-D-twcc/sites/%s/container/", twccCCSId)
-	req := r.newClient("GET", requestURL, nil)
-	resp, err := r.client.Do(req)
-
+	ccs, err := r.getTwccCCSContainer(twccCCSId)
 	if err != nil {
-		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - client.Do: %w", err)
+		return "", fmt.Errorf("TwccAdapter - getTwccCCSPodName - r.getTwccCCSContainer: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - ioutil.ReadAll: %w", err)
+	if len(ccs.Pod) == 0 {
+		return "", fmt.Errorf("TwccAdapter - getTwccCCSPodName - len(ccs.Pod) == 0")
 	}
 
-	if resp.StatusCode != http.StatusOK || string(body) == "" {
-		return "", fmt.Errorf("TwccAdapter - GetTwccCCSStatus - resp.StatusCode: %w", err)
-	}
+	return ccs.Pod[0].Name, nil
+}
 
-	var ccs GetTwccCCSStatusResponse
-	if err := json.Unmarshal(body, &ccs); err != nil {
-		return "", err
-	}
+type twccCCSPort struct {
+	TargetPort int `json:"targetPort"`
+}
 
-	return ccs.Pod[0].Name, nil
+type twccCCSActionRequest struct {
+	PodName string        `json:"pod_name"`
+	Action  string        `json:"action"`
+	Ports   []twccCCSPort `json:"ports"`
 }
 
-func (r *TwccAdapter) TwccCCSAssociateIP(twccCCSId string) error {
+func (r *TwccAdapter) TwccCCSAssociateIP(twccCCSId string, targetPorts []int) error {
 	podName, err := r.getTwccCCSPodName(twccCCSId)
 	if err != nil {
 		return fmt.Errorf("TwccAdapter - TwccCCSAssociateIP - r.getTwccCCSPodName: %w", err)
 	}
 
-	requestURL := fmt.Sprintf("https://apigateway.twcc.ai/api/v3/k8s-D-twcc/sites/%s/container/action/", twccCCSId)
-	body := bytes.NewBuffer([]byte(`{
-		"pod_name": "` + podName + `",
-		"action": "associateIP",
-		"ports": [
-			{
-			"targetPort": 5000
-			}
-		]}`))
-	req := r.newClient("PUT", requestURL, body)
+	body := twccCCSActionRequest{PodName: podName, Action: "associateIP"}
+	for _, p := range targetPorts {
+		body.Ports = append(body.Ports, twccCCSPort{TargetPort: p})
+	}
 
-	resp, err := r.client.Do(req)
+	b, err := json.Marshal(body)
 	if err != nil {
-		return fmt.Errorf("TwccAdapter - TwccCCSAssociateIP - client.Do: err: %w", err)
+		return fmt.Errorf("TwccAdapter - TwccCCSAssociateIP - json.Marshal: %w", err)
 	}
 
-	if resp.Body != nil {
-		defer resp.Body.Close()
+	requestURL := r.url("/sites/%s/container/action/", twccCCSId)
+	req, err := r.newRequest("PUT", requestURL, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("TwccAdapter - TwccCCSAssociateIP - r.newRequest: %w", err)
+	}
+
+	if _, err := r.do(req); err != nil {
+		return fmt.Errorf("TwccAdapter - TwccCCSAssociateIP - r.do: %w", err)
 	}
 
 	return nil
 }
 
 func (r *TwccAdapter) DeleteTwccCCS(twccCSSId string) error {
-	requestURL := fmt.Sprintf("https://apigateway.twcc.ai/api/v3/k8s-D-twcc/sites/%s/", twccCSSId)
-	fmt.Println(requestURL)
-	req := r.newClient("DELETE", requestURL, nil)
-	resp, err := r.client.Do(req)
-
+	requestURL := r.url("/sites/%s/", twccCSSId)
+	req, err := r.newRequest("DELETE", requestURL, nil)
 	if err != nil {
-		return fmt.Errorf("TwccAdapter - DeleteTwccCCS - client.Do: %w", err)
+		return fmt.Errorf("TwccAdapter - DeleteTwccCCS - r.newRequest: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("TwccAdapter - DeleteTwccCCS - ioutil.ReadAll: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusNoContent || string(body) != "" {
-		return fmt.Errorf("TwccAdapter - DeleteTwccCCS - resp.StatusCode: %w", err)
+	if _, err := r.do(req); err != nil {
+		return fmt.Errorf("TwccAdapter - DeleteTwccCCS - r.do: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,36 @@
+package adapter
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"golang_backend_template/internal/usecase/ports"
+)
+
+func TestTwccErrorKind(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		detail     string
+		want       error
+	}{
+		{http.StatusBadRequest, "GPU quota exceeded.", ports.ErrTwccQuotaExceeded},
+		{http.StatusForbidden, "Project quota exceeded.", ports.ErrTwccQuotaExceeded},
+		{http.StatusUnprocessableEntity, "Exceeds the quota of the project.", ports.ErrTwccQuotaExceeded},
+		{http.StatusForbidden, "Permission denied.", ports.ErrTwccUnauthorized},
+		{http.StatusUnauthorized, "Token lifetime exceeded.", ports.ErrTwccUnauthorized},
+		{http.StatusNotFound, "Quota not found.", ports.ErrTwccNotFound},
+		{http.StatusGatewayTimeout, "Upstream timeout exceeded.", ports.ErrTwccTransient},
+		{http.StatusServiceUnavailable, "Connection limit exceeded.", ports.ErrTwccTransient},
+		{http.StatusRequestTimeout, "Request time exceeded.", ports.ErrTwccTransient},
+		{http.StatusTooManyRequests, "Rate limit exceeded.", ports.ErrTwccRateLimited},
+		{http.StatusBadRequest, "Invalid body.", ports.ErrTwccPermanent},
+	}
+
+	for _, tt := range tests {
+		err := twccError(tt.statusCode, []byte(`{"detail": "`+tt.detail+`"}`))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("twccError(%d, %q) = %v, want %v", tt.statusCode, tt.detail, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package adapter_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang_backend_template/internal/infra/adapter"
+	"golang_backend_template/internal/infra/adapter/twcctest"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+func newTwccAdapter(t *testing.T) (*twcctest.Server, *adapter.TwccAdapter) {
+	t.Helper()
+	s := twcctest.NewServer()
+	t.Cleanup(s.Close)
+
+	return s, adapter.NewTwccAdapter(s.Config())
+}
+
+func jobPath(id string, action string) string {
+	path := fmt.Sprintf("/api/v3/%s/jobs/%s/", twcctest.APIHost, id)
+	if action != "" {
+		path += action + "/"
+	}
+
+	return path
+}
+
+func TestTwccAdapterJob(t *testing.T) {
+	s, a := newTwccAdapter(t)
+
+	id, err := a.CreateTwccJob(ports.TwccJobSpec{
+		Name:    "train",
+		Image:   "pytorch-24.08-py3:latest",
+		Command: []string{"python", "train.py", "--note", "it's done"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTwccJob: %v", err)
+	}
+
+	job, ok := s.Job(id)
+	if !ok {
+		t.Fatalf("job %s was not created", id)
+	}
+	if job.Project != s.Config().Project || job.Flavor != s.Config().JobFlavor {
+		t.Errorf("job project %q flavor %q, want the configured defaults", job.Project, job.Flavor)
+	}
+	if want := `python train.py --note 'it'\''s done'`; job.Command != want {
+		t.Errorf("job command %q, want %q", job.Command, want)
+	}
+
+	if err := a.RunTwccJob(id); err != nil {
+		t.Fatalf("RunTwccJob: %v", err)
+	}
+
+	for _, want := range []string{twcctest.JobStatusRunning, twcctest.JobStatusInactive} {
+		status, err := a.GetTwccJobStatus(id)
+		if err != nil {
+			t.Fatalf("GetTwccJobStatus: %v", err)
+		}
+		if status != want {
+			t.Errorf("status %q, want %q", status, want)
+		}
+	}
+
+	if err := a.CancelTwccJob(id); err != nil {
+		t.Fatalf("CancelTwccJob: %v", err)
+	}
+	if job, _ := s.Job(id); job.Submitted != 1 || job.Cancelled != 1 {
+		t.Errorf("job submitted %d cancelled %d times, want once each", job.Submitted, job.Cancelled)
+	}
+}
+
+func TestTwccAdapterJobNotFound(t *testing.T) {
+	_, a := newTwccAdapter(t)
+
+	_, err := a.GetTwccJobStatus("404")
+	if !errors.Is(err, ports.ErrTwccNotFound) {
+		t.Errorf("GetTwccJobStatus of a missing job: %v, want ErrTwccNotFound", err)
+	}
+}
+
+func TestTwccAdapterCCS(t *testing.T) {
+	s, a := newTwccAdapter(t)
+
+	id, err := a.CreateTwccCCS(ports.TwccCCSSpec{
+		JobID:   "job-1",
+		Name:    "infer",
+		Image:   "triton-24.08-py3:latest",
+		Flavor:  "1 GPU + 04 cores + 090GB memory",
+		Replica: 1,
+		Mounts:  map[string]string{"gpfs01": "/work"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTwccCCS: %v", err)
+	}
+
+	site, ok := s.Site(id)
+	if !ok {
+		t.Fatalf("site %s was not created", id)
+	}
+	if site.Image != "triton-24.08-py3:latest" || site.Flavor != "1 GPU + 04 cores + 090GB memory" || site.Replica != "1" {
+		t.Errorf("site image %q flavor %q replica %q", site.Image, site.Flavor, site.Replica)
+	}
+	if site.Mounts["gpfs01"] != "/work" {
+		t.Errorf("site mounts %v, want gpfs01 on /work", site.Mounts)
+	}
+
+	if _, err := a.GetTwccCCSEntryPoint(id, 8888); !errors.Is(err, ports.ErrTwccCCSNotReady) {
+		t.Errorf("GetTwccCCSEntryPoint before the IP was associated: %v, want ErrTwccCCSNotReady", err)
+	}
+
+	status, err := a.GetTwccCCSStatus(id)
+	if err != nil || status != twcctest.SiteStatusReady {
+		t.Fatalf("GetTwccCCSStatus: %q, %v", status, err)
+	}
+
+	if err := a.TwccCCSAssociateIP(id, []int{8888}); err != nil {
+		t.Fatalf("TwccCCSAssociateIP: %v", err)
+	}
+
+	entryPoint, err := a.GetTwccCCSEntryPoint(id, 8888)
+	if err != nil {
+		t.Fatalf("GetTwccCCSEntryPoint: %v", err)
+	}
+	if site, _ := s.Site(id); !strings.HasPrefix(entryPoint, site.PublicIP+":") {
+		t.Errorf("entry point %q, want an address on %s", entryPoint, site.PublicIP)
+	}
+
+	list, err := a.ListTwccCCS("")
+	if err != nil {
+		t.Fatalf("ListTwccCCS: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != id || list[0].JobID != "job-1" {
+		t.Errorf("ListTwccCCS: %+v, want site %s of job-1", list, id)
+	}
+
+	if list, err := a.ListTwccCCS("20000"); err != nil || len(list) != 0 {
+		t.Errorf("ListTwccCCS of another project: %+v, %v", list, err)
+	}
+
+	if err := a.DeleteTwccCCS(id); err != nil {
+		t.Fatalf("DeleteTwccCCS: %v", err)
+	}
+	if err := a.DeleteTwccCCS(id); !errors.Is(err, ports.ErrTwccNotFound) {
+		t.Errorf("DeleteTwccCCS of a deleted site: %v, want ErrTwccNotFound", err)
+	}
+}
+
+func TestTwccAdapterRetry(t *testing.T) {
+	s, a := newTwccAdapter(t)
+	s.AddJob("1")
+	s.AddJob("2")
+
+	s.Fail(http.MethodPost, jobPath("1", "submit"), http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	if err := a.RunTwccJob("1"); err != nil {
+		t.Fatalf("RunTwccJob after unavailable responses: %v", err)
+	}
+	if job, _ := s.Job("1"); job.Submitted != 1 {
+		t.Errorf("job submitted %d times, want once", job.Submitted)
+	}
+
+	s.Fail(http.MethodGet, jobPath("1", ""), http.StatusBadGateway, http.StatusGatewayTimeout)
+	if _, err := a.GetTwccJobStatus("1"); err != nil {
+		t.Errorf("GetTwccJobStatus after gateway errors: %v", err)
+	}
+
+	// The gateway may have passed a failed submit on, it must not be sent
+	// again.
+	s.Fail(http.MethodPost, jobPath("2", "submit"), http.StatusBadGateway)
+	if err := a.RunTwccJob("2"); !errors.Is(err, ports.ErrTwccTransient) {
+		t.Errorf("RunTwccJob after a bad gateway: %v, want ErrTwccTransient", err)
+	}
+	if job, _ := s.Job("2"); job.Submitted != 0 {
+		t.Errorf("job submitted %d times, want never", job.Submitted)
+	}
+}
+
+func TestTwccAdapterPoolCCS(t *testing.T) {
+	s, a := newTwccAdapter(t)
+
+	id, err := a.CreateTwccCCS(ports.TwccCCSSpec{
+		Name:     "inference-pool",
+		Image:    "triton-24.08-py3:latest",
+		Flavor:   "1 GPU + 04 cores + 090GB memory",
+		PoolSpec: "0123456789abcdef",
+	})
+	if err != nil {
+		t.Fatalf("CreateTwccCCS: %v", err)
+	}
+	if site, _ := s.Site(id); site.Desc != ports.PoolSpecLabel+"=0123456789abcdef" {
+		t.Errorf("site desc %q, want the pool spec label", site.Desc)
+	}
+
+	list, err := a.ListTwccCCS("")
+	if err != nil {
+		t.Fatalf("ListTwccCCS: %v", err)
+	}
+	if len(list) != 1 || list[0].PoolSpec != "0123456789abcdef" || list[0].JobID != "" {
+		t.Errorf("ListTwccCCS: %+v, want pool site %s", list, id)
+	}
+}
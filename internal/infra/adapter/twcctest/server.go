@@ -0,0 +1,528 @@
+// Package twcctest provides a fake TWCC API gateway for running the TWCC
+// adapter and the job managers offline.
+package twcctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang_backend_template/internal/infra/adapter"
+)
+
+const (
+	APIKey  = "twcctest-api-key"
+	APIHost = "k8s-D-twcc"
+)
+
+// Job statuses reported by TWCC, a job is Inactive before it was submitted
+// and again once it finished.
+const (
+	JobStatusInactive = "Inactive"
+	JobStatusQueued   = "Queued"
+	JobStatusRunning  = "Running"
+	JobStatusFailed   = "Failed"
+)
+
+// Site statuses reported by TWCC for a CCS.
+const (
+	SiteStatusPending = "Pending"
+	SiteStatusReady   = "Ready"
+	SiteStatusError   = "Error"
+)
+
+// Job is a TWCC job of the fake gateway. Script holds the statuses reported
+// after the job was submitted, one per status request, the last one sticks.
+type Job struct {
+	ID string
+	// Name, Project, Image, Flavor and Command are set for jobs created
+	// through the API.
+	Name      string
+	Project   string
+	Image     string
+	Flavor    string
+	Command   string
+	Status    string
+	Script    []string
+	Submitted int
+	Cancelled int
+}
+
+// Port is a port of a CCS service, Port is the public port once an IP has
+// been associated.
+type Port struct {
+	Name       string `json:"name"`
+	TargetPort int    `json:"target_port"`
+	Port       int    `json:"port"`
+	Protocol   string `json:"protocol"`
+	NodePort   int    `json:"node_port"`
+}
+
+// Site is a CCS of the fake gateway. Like Job.Script, Script holds the
+// statuses reported by the following status requests.
+type Site struct {
+	ID      string
+	Name    string
+	Desc    string
+	Project string
+	Image   string
+	Flavor  string
+	Replica string
+	// Mounts maps the storage, like gpfs01, to its mount path.
+	Mounts   map[string]string
+	Status   string
+	Script   []string
+	PodName  string
+	PublicIP string
+	Ports    []Port
+	Deleted  bool
+}
+
+// Server is a fake TWCC gateway backed by an httptest.Server. It checks the
+// API key and host of every request and keeps its state in memory.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	jobs       map[string]*Job
+	sites      map[string]*Site
+	nextSiteID int
+	nextJobID  int
+	// failures maps "METHOD /path/" to the status codes returned by the next
+	// requests, see Fail.
+	failures map[string][]int
+	// DefaultJobScript is used by jobs added without a script.
+	DefaultJobScript []string
+	// DefaultSiteScript is used by every site created through the API.
+	DefaultSiteScript []string
+}
+
+// NewServer starts a fake gateway, Close stops it.
+func NewServer() *Server {
+	s := &Server{
+		jobs:              make(map[string]*Job),
+		sites:             make(map[string]*Site),
+		nextSiteID:        1000,
+		nextJobID:         5000,
+		failures:          make(map[string][]int),
+		DefaultJobScript:  []string{JobStatusRunning, JobStatusInactive},
+		DefaultSiteScript: []string{SiteStatusReady},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Config points a TwccAdapter at the fake gateway, with short retry delays.
+func (s *Server) Config() adapter.TwccConfig {
+	return adapter.TwccConfig{
+		BaseURL:    s.URL,
+		APIHost:    APIHost,
+		APIVersion: "v3",
+		APIKey:     APIKey,
+		Project:    "10000",
+		JobFlavor:  "1 GPU + 04 cores + 090GB memory",
+		Timeout:    10 * time.Second,
+		Retry: adapter.RetryConfig{
+			MaxRetries: 3,
+			BaseDelay:  10 * time.Millisecond,
+			MaxDelay:   100 * time.Millisecond,
+		},
+	}
+}
+
+// AddJob registers a job that reports script once it was submitted.
+func (s *Server) AddJob(id string, script ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[id] = &Job{ID: id, Status: JobStatusInactive, Script: script}
+}
+
+// Job returns a copy of a job.
+func (s *Server) Job(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+
+	return *j, true
+}
+
+// ScriptSite replaces the statuses a site reports next.
+func (s *Server) ScriptSite(id string, script ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if site, ok := s.sites[id]; ok {
+		site.Script = script
+	}
+}
+
+// Site returns a copy of a site.
+func (s *Server) Site(id string) (Site, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	site, ok := s.sites[id]
+	if !ok {
+		return Site{}, false
+	}
+
+	c := *site
+	c.Ports = append([]Port(nil), site.Ports...)
+
+	return c, true
+}
+
+// Sites returns copies of all sites that have not been deleted.
+func (s *Server) Sites() []Site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sites := make([]Site, 0, len(s.sites))
+	for _, site := range s.sites {
+		if !site.Deleted {
+			sites = append(sites, *site)
+		}
+	}
+
+	return sites
+}
+
+// Fail makes the next requests of method to path, like
+// /api/v3/k8s-D-twcc/jobs/1/, fail with the given status codes in order.
+func (s *Server) Fail(method string, path string, statusCodes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := method + " " + path
+	s.failures[key] = append(s.failures[key], statusCodes...)
+}
+
+// next pops the next status of a script, the last status sticks.
+func next(status *string, script *[]string) {
+	if len(*script) == 0 {
+		return
+	}
+
+	*status = (*script)[0]
+	if len(*script) > 1 {
+		*script = (*script)[1:]
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Header.Get("x-API-KEY") != APIKey {
+		writeError(w, http.StatusUnauthorized, "Invalid API key.")
+		return
+	}
+
+	if req.Header.Get("X-API-HOST") != APIHost {
+		writeError(w, http.StatusBadRequest, "Invalid API host.")
+		return
+	}
+
+	key := req.Method + " " + req.URL.Path
+	if codes := s.failures[key]; len(codes) > 0 {
+		s.failures[key] = codes[1:]
+		writeError(w, codes[0], http.StatusText(codes[0]))
+
+		return
+	}
+
+	// Paths look like /api/<version>/<host>/<resource>/<id>/<action>/.
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "api" || parts[2] != APIHost {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	switch parts[3] {
+	case "jobs":
+		s.serveJobs(w, req, parts[4:])
+	case "sites":
+		s.serveSites(w, req, parts[4:])
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+func (s *Server) serveJobs(w http.ResponseWriter, req *http.Request, parts []string) {
+	if len(parts) == 0 {
+		if req.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+			return
+		}
+
+		s.createJob(w, req)
+
+		return
+	}
+
+	j, ok := s.jobs[parts[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && req.Method == http.MethodGet:
+		if j.Submitted > 0 {
+			next(&j.Status, &j.Script)
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id":     atoi(j.ID),
+			"type":   "job",
+			"status": j.Status,
+			"name":   "job-" + j.ID,
+		})
+	case len(parts) == 2 && parts[1] == "submit" && req.Method == http.MethodPost:
+		if j.Status != JobStatusInactive && j.Submitted > 0 {
+			writeError(w, http.StatusConflict, "Job is already running.")
+			return
+		}
+
+		j.Submitted++
+		if j.Script == nil {
+			j.Script = append([]string(nil), s.DefaultJobScript...)
+		}
+		j.Status = JobStatusQueued
+		w.WriteHeader(http.StatusCreated)
+	case len(parts) == 2 && parts[1] == "cancel" && req.Method == http.MethodPost:
+		j.Cancelled++
+		j.Status = JobStatusInactive
+		j.Script = nil
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+	}
+}
+
+func (s *Server) createJob(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Name    string `json:"name"`
+		Project string `json:"project"`
+		Image   string `json:"image"`
+		Flavor  string `json:"flavor"`
+		Command string `json:"cmd"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid body.")
+		return
+	}
+
+	if body.Project == "" || body.Image == "" {
+		writeError(w, http.StatusBadRequest, "Project and image are required.")
+		return
+	}
+
+	s.nextJobID++
+	id := strconv.Itoa(s.nextJobID)
+	s.jobs[id] = &Job{
+		ID:      id,
+		Name:    body.Name,
+		Project: body.Project,
+		Image:   body.Image,
+		Flavor:  body.Flavor,
+		Command: body.Command,
+		Status:  JobStatusInactive,
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":      s.nextJobID,
+		"name":    body.Name,
+		"status":  JobStatusInactive,
+		"project": atoi(body.Project),
+	})
+}
+
+func (s *Server) serveSites(w http.ResponseWriter, req *http.Request, parts []string) {
+	if len(parts) == 0 {
+		switch req.Method {
+		case http.MethodPost:
+			s.createSite(w, req)
+		case http.MethodGet:
+			s.listSites(w, req)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		}
+
+		return
+	}
+
+	site, ok := s.sites[parts[0]]
+	if !ok || site.Deleted {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && req.Method == http.MethodGet:
+		next(&site.Status, &site.Script)
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id":     atoi(site.ID),
+			"name":   site.Name,
+			"status": site.Status,
+		})
+	case len(parts) == 1 && req.Method == http.MethodDelete:
+		site.Deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2 && parts[1] == "container" && req.Method == http.MethodGet:
+		var publicIP []string
+		if site.PublicIP != "" {
+			publicIP = []string{site.PublicIP}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"Service": []map[string]any{{
+				"name":       site.Name,
+				"net_type":   "NodePort",
+				"cluster_ip": "10.0.0.1",
+				"public_ip":  publicIP,
+				"ports":      site.Ports,
+			}},
+			"Pod": []map[string]any{{"name": site.PodName}},
+		})
+	case len(parts) == 3 && parts[1] == "container" && parts[2] == "action" && req.Method == http.MethodPut:
+		s.associateIP(w, req, site)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+	}
+}
+
+// listSites lists the sites of the project given by the project query
+// parameter, without their status advancing.
+func (s *Server) listSites(w http.ResponseWriter, req *http.Request) {
+	project := req.URL.Query().Get("project")
+	if project == "" {
+		writeError(w, http.StatusBadRequest, "Project is required.")
+		return
+	}
+
+	ids := make([]string, 0, len(s.sites))
+	for id := range s.sites {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	sites := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		site := s.sites[id]
+		if site.Deleted || site.Project != project {
+			continue
+		}
+
+		sites = append(sites, map[string]any{
+			"id":     atoi(site.ID),
+			"name":   site.Name,
+			"desc":   site.Desc,
+			"status": site.Status,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, sites)
+}
+
+func (s *Server) createSite(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Name     string `json:"name"`
+		Desc     string `json:"desc"`
+		Project  int    `json:"project"`
+		Solution int    `json:"solution"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Project == 0 || body.Solution == 0 {
+		writeError(w, http.StatusBadRequest, "Invalid body.")
+		return
+	}
+
+	mounts := make(map[string]string)
+	for key, values := range req.Header {
+		property, ok := strings.CutPrefix(strings.ToLower(key), "x-extra-property-")
+		if storage, isMount := strings.CutSuffix(property, "-mount-path"); ok && isMount {
+			mounts[storage] = values[0]
+		}
+	}
+
+	s.nextSiteID++
+	id := strconv.Itoa(s.nextSiteID)
+	site := &Site{
+		ID:      id,
+		Name:    body.Name,
+		Desc:    body.Desc,
+		Project: strconv.Itoa(body.Project),
+		Image:   req.Header.Get("x-extra-property-image"),
+		Flavor:  req.Header.Get("x-extra-property-flavor"),
+		Replica: req.Header.Get("x-extra-property-replica"),
+		Mounts:  mounts,
+		Status:  SiteStatusPending,
+		Script:  append([]string(nil), s.DefaultSiteScript...),
+		PodName: fmt.Sprintf("%s-%s-pod", body.Name, id),
+		Ports: []Port{
+			{Name: "ssh", TargetPort: 22, Port: 22, Protocol: "TCP", NodePort: 30022},
+			{Name: "jupyter", TargetPort: 8888, Port: 8888, Protocol: "TCP", NodePort: 30888},
+		},
+	}
+	s.sites[id] = site
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":     s.nextSiteID,
+		"name":   site.Name,
+		"status": site.Status,
+	})
+}
+
+func (s *Server) associateIP(w http.ResponseWriter, req *http.Request, site *Site) {
+	var body struct {
+		PodName string `json:"pod_name"`
+		Action  string `json:"action"`
+		Ports   []struct {
+			TargetPort int `json:"targetPort"`
+		} `json:"ports"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Action != "associateIP" {
+		writeError(w, http.StatusBadRequest, "Invalid body.")
+		return
+	}
+
+	if body.PodName != site.PodName {
+		writeError(w, http.StatusNotFound, "Pod not found.")
+		return
+	}
+
+	site.PublicIP = "203.0.113." + strconv.Itoa(len(s.sites)%250+1)
+	for _, p := range body.Ports {
+		site.Ports = append(site.Ports, Port{
+			Name:       "port-" + strconv.Itoa(p.TargetPort),
+			TargetPort: p.TargetPort,
+			Port:       50000 + len(site.Ports),
+			Protocol:   "TCP",
+			NodePort:   31000 + len(site.Ports),
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers like the TWCC gateway, with the message in detail.
+func writeError(w http.ResponseWriter, code int, detail string) {
+	writeJSON(w, code, map[string]string{"detail": detail})
+}
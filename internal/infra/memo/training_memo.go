@@ -2,6 +2,7 @@ package memo
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"golang_backend_template/internal/usecase/entity"
@@ -9,22 +10,45 @@ import (
 
 type TrainingJobsMemory struct {
 	mu            sync.Mutex
+	queuedJobs    map[string]entity.QueuedJob
 	twccJobs      map[string]entity.TwccJob
 	containerJobs map[string]entity.ContainerJob
 	jobHistory    map[string]entity.GenericJob
+	// containerHistory keeps finished container jobs, whose container may
+	// still be around.
+	containerHistory map[string]entity.ContainerJob
 }
 
 func NewTrainingJobsMemory() *TrainingJobsMemory {
 	return &TrainingJobsMemory{
-		twccJobs:      make(map[string]entity.TwccJob),
-		containerJobs: make(map[string]entity.ContainerJob),
-		jobHistory:    make(map[string]entity.GenericJob),
+		queuedJobs:       make(map[string]entity.QueuedJob),
+		twccJobs:         make(map[string]entity.TwccJob),
+		containerJobs:    make(map[string]entity.ContainerJob),
+		jobHistory:       make(map[string]entity.GenericJob),
+		containerHistory: make(map[string]entity.ContainerJob),
 	}
 }
 
+// unlink removes the job from every active list, so that a push moves the
+// job instead of duplicating it.
+func (r *TrainingJobsMemory) unlink(id string) {
+	delete(r.queuedJobs, id)
+	delete(r.twccJobs, id)
+	delete(r.containerJobs, id)
+}
+
+func (r *TrainingJobsMemory) PushQueuedJob(j entity.QueuedJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unlink(j.Job.ID)
+	r.queuedJobs[j.Job.ID] = j
+	return nil
+}
+
 func (r *TrainingJobsMemory) PushTwccJob(j entity.TwccJob) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.unlink(j.Job.ID)
 	r.twccJobs[j.Job.ID] = j
 	return nil
 }
@@ -32,11 +56,18 @@ func (r *TrainingJobsMemory) PushTwccJob(j entity.TwccJob) error {
 func (r *TrainingJobsMemory) PushContainerJob(j entity.ContainerJob) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.unlink(j.Job.ID)
 	r.containerJobs[j.Job.ID] = j
 	return nil
 }
 
 func (r *TrainingJobsMemory) GetJob(id string) (entity.GenericJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if j, ok := r.queuedJobs[id]; ok {
+		return j.Job, nil
+	}
+
 	if j, ok := r.twccJobs[id]; ok {
 		return j.Job, nil
 	}
@@ -52,7 +83,41 @@ func (r *TrainingJobsMemory) GetJob(id string) (entity.GenericJob, error) {
 	return entity.GenericJob{}, fmt.Errorf("TrainingJobsMemory - GetJob - job not found")
 }
 
+func (r *TrainingJobsMemory) GetContainerJob(id string) (entity.ContainerJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if j, ok := r.containerJobs[id]; ok {
+		return j, nil
+	}
+
+	if j, ok := r.containerHistory[id]; ok {
+		j.Job = r.jobHistory[id]
+		return j, nil
+	}
+
+	return entity.ContainerJob{}, fmt.Errorf("TrainingJobsMemory - GetContainerJob - job not found")
+}
+
+// GetQueuedJobList returns the queued jobs in the order they were enqueued.
+func (r *TrainingJobsMemory) GetQueuedJobList() ([]entity.QueuedJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobs := make([]entity.QueuedJob, 0, 64)
+
+	for _, j := range r.queuedJobs {
+		jobs = append(jobs, j)
+	}
+
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].EnqueuedAt.Before(jobs[b].EnqueuedAt)
+	})
+
+	return jobs, nil
+}
+
 func (r *TrainingJobsMemory) GetTwccJobList() ([]entity.TwccJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	jobs := make([]entity.TwccJob, 0, 64)
 
 	for _, j := range r.twccJobs {
@@ -63,6 +128,8 @@ func (r *TrainingJobsMemory) GetTwccJobList() ([]entity.TwccJob, error) {
 }
 
 func (r *TrainingJobsMemory) GetContainerJobList() ([]entity.ContainerJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	jobs := make([]entity.ContainerJob, 0, 64)
 
 	for _, j := range r.containerJobs {
@@ -73,6 +140,8 @@ func (r *TrainingJobsMemory) GetContainerJobList() ([]entity.ContainerJob, error
 }
 
 func (r *TrainingJobsMemory) GetHistoryJobList() ([]entity.GenericJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	jobs := make([]entity.GenericJob, 0, 64)
 
 	for _, j := range r.jobHistory {
@@ -83,11 +152,24 @@ func (r *TrainingJobsMemory) GetHistoryJobList() ([]entity.GenericJob, error) {
 }
 
 func (r *TrainingJobsMemory) storeHistory(job entity.GenericJob) {
-	job.Status = "finished"
 	r.jobHistory[job.ID] = job
 }
 
+func (r *TrainingJobsMemory) DeleteQueuedJob(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.queuedJobs[id]; ok {
+		r.storeHistory(r.queuedJobs[id].Job)
+		delete(r.queuedJobs, id)
+		return nil
+	}
+
+	return fmt.Errorf("TrainingJobsMemory - DeleteQueuedJob - job not found")
+}
+
 func (r *TrainingJobsMemory) DeleteTwccJob(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if _, ok := r.twccJobs[id]; ok {
 		r.storeHistory(r.twccJobs[id].Job)
 		delete(r.twccJobs, id)
@@ -98,8 +180,11 @@ func (r *TrainingJobsMemory) DeleteTwccJob(id string) error {
 }
 
 func (r *TrainingJobsMemory) DeleteContainerJob(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if _, ok := r.containerJobs[id]; ok {
 		r.storeHistory(r.containerJobs[id].Job)
+		r.containerHistory[id] = r.containerJobs[id]
 		delete(r.containerJobs, id)
 		return nil
 	}
@@ -0,0 +1,92 @@
+package memo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+type WebhooksMemory struct {
+	mu         sync.Mutex
+	webhooks   map[string]entity.Webhook
+	deliveries map[string]entity.WebhookDelivery
+}
+
+func NewWebhooksMemory() *WebhooksMemory {
+	return &WebhooksMemory{
+		webhooks:   make(map[string]entity.Webhook),
+		deliveries: make(map[string]entity.WebhookDelivery),
+	}
+}
+
+func (r *WebhooksMemory) StoreWebhook(w entity.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[w.JobID] = w
+	return nil
+}
+
+func (r *WebhooksMemory) GetWebhook(jobID string) (entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w, ok := r.webhooks[jobID]; ok {
+		return w, nil
+	}
+
+	return entity.Webhook{}, fmt.Errorf("WebhooksMemory - GetWebhook - %w", ports.ErrWebhookNotFound)
+}
+
+func (r *WebhooksMemory) DeleteWebhook(jobID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.webhooks, jobID)
+	return nil
+}
+
+func (r *WebhooksMemory) StoreWebhookDelivery(d entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[d.ID] = d
+	return nil
+}
+
+// deliveriesWhere returns the matching deliveries oldest first, r.mu must be
+// held.
+func (r *WebhooksMemory) deliveriesWhere(match func(entity.WebhookDelivery) bool) []entity.WebhookDelivery {
+	deliveries := make([]entity.WebhookDelivery, 0, 8)
+	for _, d := range r.deliveries {
+		if match(d) {
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+		}
+		return deliveries[i].EventID < deliveries[j].EventID
+	})
+
+	return deliveries
+}
+
+func (r *WebhooksMemory) GetWebhookDeliveries(jobID string) ([]entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deliveriesWhere(func(d entity.WebhookDelivery) bool {
+		return d.JobID == jobID
+	}), nil
+}
+
+func (r *WebhooksMemory) GetPendingWebhookDeliveries() ([]entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deliveriesWhere(func(d entity.WebhookDelivery) bool {
+		return d.Status == entity.WebhookDeliveryPending
+	}), nil
+}
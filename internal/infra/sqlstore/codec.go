@@ -0,0 +1,29 @@
+package sqlstore
+
+import (
+	"encoding/json"
+)
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// jsonColumn stores nested job fields as JSON text, which keeps the schema
+// portable between SQLite and PostgreSQL.
+func jsonColumn(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func fromJSONColumn(s string, v any) error {
+	if s == "" {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(s), v)
+}
@@ -0,0 +1,134 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+const inferenceJobColumns = `id, name, status, transitions, twcc_ccs_id, entry_point, spec, ttl, expires_at,
+	failure_reason, attempts`
+
+func scanInferenceJob(s scanner) (entity.InferenceJob, error) {
+	var (
+		j           entity.InferenceJob
+		transitions string
+		spec        string
+		expiresAt   sql.NullTime
+		attempts    string
+	)
+
+	err := s.Scan(&j.Job.ID, &j.Job.Name, &j.Job.Status, &transitions, &j.TwccCCSId, &j.EntryPoint, &spec,
+		&j.TTL, &expiresAt, &j.Job.FailureReason, &attempts)
+	if err != nil {
+		return entity.InferenceJob{}, err
+	}
+
+	if err := fromJSONColumn(transitions, &j.Job.Transitions); err != nil {
+		return entity.InferenceJob{}, err
+	}
+
+	if err := fromJSONColumn(spec, &j.Spec); err != nil {
+		return entity.InferenceJob{}, err
+	}
+
+	if err := fromJSONColumn(attempts, &j.Attempts); err != nil {
+		return entity.InferenceJob{}, err
+	}
+
+	if expiresAt.Valid {
+		j.ExpiresAt = expiresAt.Time
+	}
+
+	return j, nil
+}
+
+type InferenceJobsStore struct {
+	db *sql.DB
+}
+
+func NewInferenceJobsStore(db *sql.DB) *InferenceJobsStore {
+	return &InferenceJobsStore{db: db}
+}
+
+func (r *InferenceJobsStore) StoreInferenceJob(j entity.InferenceJob) error {
+	transitions, err := jsonColumn(j.Job.Transitions)
+	if err != nil {
+		return fmt.Errorf("InferenceJobsStore - StoreInferenceJob - jsonColumn: %w", err)
+	}
+
+	spec, err := jsonColumn(j.Spec)
+	if err != nil {
+		return fmt.Errorf("InferenceJobsStore - StoreInferenceJob - jsonColumn: %w", err)
+	}
+
+	attempts, err := jsonColumn(j.Attempts)
+	if err != nil {
+		return fmt.Errorf("InferenceJobsStore - StoreInferenceJob - jsonColumn: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO inference_jobs (id, name, status, transitions, twcc_ccs_id, entry_point, spec, ttl, expires_at,
+			failure_reason, attempts, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			status = excluded.status,
+			transitions = excluded.transitions,
+			twcc_ccs_id = excluded.twcc_ccs_id,
+			entry_point = excluded.entry_point,
+			spec = excluded.spec,
+			ttl = excluded.ttl,
+			expires_at = excluded.expires_at,
+			failure_reason = excluded.failure_reason,
+			attempts = excluded.attempts`,
+		j.Job.ID, j.Job.Name, j.Job.Status, transitions, j.TwccCCSId, j.EntryPoint, spec,
+		j.TTL, sql.NullTime{Time: j.ExpiresAt.UTC(), Valid: !j.ExpiresAt.IsZero()},
+		j.Job.FailureReason, attempts, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("InferenceJobsStore - StoreInferenceJob - r.db.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *InferenceJobsStore) GetInferenceJob(id string) (entity.InferenceJob, error) {
+	j, err := scanInferenceJob(r.db.QueryRow(`SELECT `+inferenceJobColumns+` FROM inference_jobs WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return entity.InferenceJob{}, fmt.Errorf("InferenceJobsStore - GetInferenceJob - job not found")
+	}
+	if err != nil {
+		return entity.InferenceJob{}, fmt.Errorf("InferenceJobsStore - GetInferenceJob - scanInferenceJob: %w", err)
+	}
+
+	return j, nil
+}
+
+func (r *InferenceJobsStore) GetAllInferenceJob() ([]entity.InferenceJob, error) {
+	rows, err := r.db.Query(`SELECT ` + inferenceJobColumns + ` FROM inference_jobs ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("InferenceJobsStore - GetAllInferenceJob - r.db.Query: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]entity.InferenceJob, 0, 64)
+	for rows.Next() {
+		j, err := scanInferenceJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("InferenceJobsStore - GetAllInferenceJob - scanInferenceJob: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (r *InferenceJobsStore) DeleteInferenceJob(id string) error {
+	if _, err := r.db.Exec(`DELETE FROM inference_jobs WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("InferenceJobsStore - DeleteInferenceJob - r.db.Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package sqlstore_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/sqlstore"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+func TestInferenceJobsStoreRoundTrip(t *testing.T) {
+	r := sqlstore.NewInferenceJobsStore(newDB(t))
+
+	job := entity.InferenceJob{
+		Job: runningJob("job-1"),
+		Spec: entity.InferenceSpec{
+			Image:   "triton-24.08-py3:latest",
+			Flavor:  "1 GPU + 04 cores + 090GB memory",
+			Replica: 1,
+			Ports:   []int{8000, 8001},
+			Mounts:  []entity.InferenceMount{{Storage: "gpfs01", Path: "/work"}},
+		},
+		TwccCCSId:  "1001",
+		EntryPoint: "203.0.113.1:50002",
+		TTL:        30 * time.Minute,
+		ExpiresAt:  _at.Add(30 * time.Minute),
+		Attempts: []entity.ProvisioningAttempt{{
+			StartedAt:     _at,
+			FailedAt:      _at.Add(time.Minute),
+			Step:          "wait for entry point",
+			TwccCCSId:     "1000",
+			Reason:        "timed out",
+			RollbackError: "twcc: not found",
+		}},
+	}
+	// Inference jobs have no priority.
+	job.Job.Priority = ""
+	if err := r.StoreInferenceJob(job); err != nil {
+		t.Fatalf("StoreInferenceJob: %v", err)
+	}
+
+	got, err := r.GetInferenceJob("job-1")
+	if err != nil {
+		t.Fatalf("GetInferenceJob: %v", err)
+	}
+	if !reflect.DeepEqual(got, job) {
+		t.Fatalf("job %+v, want %+v", got, job)
+	}
+
+	// Storing the job again updates it in place.
+	job.Job.Status = entity.JobStateExpired
+	job.Job.Transitions = append(job.Job.Transitions, entity.JobTransition{From: entity.JobStateRunning, To: entity.JobStateExpired, At: _at.Add(time.Hour)})
+	job.EntryPoint = ""
+	job.ExpiresAt = time.Time{}
+	if err := r.StoreInferenceJob(job); err != nil {
+		t.Fatalf("StoreInferenceJob again: %v", err)
+	}
+
+	jobs, err := r.GetAllInferenceJob()
+	if err != nil {
+		t.Fatalf("GetAllInferenceJob: %v", err)
+	}
+	if len(jobs) != 1 || !reflect.DeepEqual(jobs[0], job) {
+		t.Fatalf("jobs %+v, want %+v", jobs, job)
+	}
+
+	if err := r.DeleteInferenceJob("job-1"); err != nil {
+		t.Fatalf("DeleteInferenceJob: %v", err)
+	}
+	if _, err := r.GetInferenceJob("job-1"); err == nil {
+		t.Error("GetInferenceJob of a deleted job succeeded")
+	}
+}
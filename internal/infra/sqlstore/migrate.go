@@ -0,0 +1,74 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+// migrate applies the embedded migrations of the given driver in file name
+// order. Applied versions are tracked in the schema_migrations table.
+func migrate(db *sql.DB, driver string) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY)`)
+	if err != nil {
+		return fmt.Errorf("sqlstore - migrate - create schema_migrations: %w", err)
+	}
+
+	dir := "migrations/" + driver
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return fmt.Errorf("sqlstore - migrate - fs.ReadDir: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := applyMigration(db, dir, name); err != nil {
+			return fmt.Errorf("sqlstore - migrate - %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, dir string, name string) error {
+	var applied int
+	err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = $1`, name).Scan(&applied)
+	if err != nil {
+		return fmt.Errorf("check version: %w", err)
+	}
+
+	if applied > 0 {
+		return nil
+	}
+
+	stmt, err := fs.ReadFile(migrations, dir+"/"+name)
+	if err != nil {
+		return fmt.Errorf("fs.ReadFile: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("db.Begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(string(stmt)); err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, name); err != nil {
+		return fmt.Errorf("record version: %w", err)
+	}
+
+	return tx.Commit()
+}
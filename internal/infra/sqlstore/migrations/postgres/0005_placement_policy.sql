@@ -0,0 +1 @@
+ALTER TABLE training_jobs ADD COLUMN placement_policy TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE training_jobs ADD COLUMN exit_code BIGINT;
+ALTER TABLE training_jobs ADD COLUMN oom_killed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE training_jobs ADD COLUMN failure_reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE training_jobs ADD COLUMN spec TEXT NOT NULL DEFAULT '{}';
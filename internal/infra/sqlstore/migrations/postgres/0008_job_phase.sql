@@ -0,0 +1,2 @@
+ALTER TABLE training_jobs ADD COLUMN phase TEXT NOT NULL DEFAULT '';
+ALTER TABLE training_jobs ADD COLUMN pull_progress TEXT NOT NULL DEFAULT 'null';
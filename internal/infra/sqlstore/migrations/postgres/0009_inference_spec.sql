@@ -0,0 +1 @@
+ALTER TABLE inference_jobs ADD COLUMN spec TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+ALTER TABLE inference_jobs ADD COLUMN ttl BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE inference_jobs ADD COLUMN expires_at TIMESTAMPTZ NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE webhooks (
+    job_id      TEXT PRIMARY KEY,
+    kind        TEXT NOT NULL,
+    url         TEXT NOT NULL,
+    secret      TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id               BIGINT PRIMARY KEY,
+    job_id           TEXT NOT NULL,
+    url              TEXT NOT NULL,
+    event            TEXT NOT NULL,
+    status           TEXT NOT NULL,
+    attempts         TEXT NOT NULL DEFAULT '[]',
+    next_attempt_at  TIMESTAMPTZ NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_job_id ON webhook_deliveries (job_id);
+CREATE INDEX webhook_deliveries_status ON webhook_deliveries (status);
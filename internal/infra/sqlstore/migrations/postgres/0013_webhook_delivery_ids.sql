@@ -0,0 +1,4 @@
+ALTER TABLE webhook_deliveries ADD COLUMN event_id BIGINT;
+UPDATE webhook_deliveries SET event_id = id;
+ALTER TABLE webhook_deliveries ALTER COLUMN event_id SET NOT NULL;
+ALTER TABLE webhook_deliveries ALTER COLUMN id TYPE TEXT USING id::TEXT;
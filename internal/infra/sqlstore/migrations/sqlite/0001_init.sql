@@ -0,0 +1,21 @@
+CREATE TABLE training_jobs (
+    id                TEXT PRIMARY KEY,
+    name              TEXT NOT NULL,
+    status            TEXT NOT NULL,
+    backend           TEXT NOT NULL,
+    docker_image_name TEXT NOT NULL DEFAULT '',
+    twcc_job_id       TEXT NOT NULL DEFAULT '',
+    finished          BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at        TIMESTAMP NOT NULL
+);
+
+CREATE INDEX training_jobs_backend_finished ON training_jobs (backend, finished);
+
+CREATE TABLE inference_jobs (
+    id           TEXT PRIMARY KEY,
+    name         TEXT NOT NULL,
+    status       TEXT NOT NULL,
+    twcc_ccs_id  TEXT NOT NULL DEFAULT '',
+    entry_point  TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL
+);
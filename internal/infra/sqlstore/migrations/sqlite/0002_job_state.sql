@@ -0,0 +1,8 @@
+ALTER TABLE training_jobs ADD COLUMN transitions TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE inference_jobs ADD COLUMN transitions TEXT NOT NULL DEFAULT '[]';
+
+UPDATE training_jobs SET status = 'succeeded' WHERE status = 'finished';
+UPDATE training_jobs SET status = 'running' WHERE status IN ('running on docker', 'running on twcc');
+UPDATE training_jobs SET status = 'pending' WHERE status = 'created';
+UPDATE inference_jobs SET status = 'running' WHERE status = 'inference running on twcc';
+UPDATE inference_jobs SET status = 'pending' WHERE status = 'created';
@@ -0,0 +1 @@
+ALTER TABLE training_jobs ADD COLUMN enqueued_at TIMESTAMP NULL;
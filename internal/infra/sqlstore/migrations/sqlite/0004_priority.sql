@@ -0,0 +1,2 @@
+ALTER TABLE training_jobs ADD COLUMN priority TEXT NOT NULL DEFAULT 'normal';
+ALTER TABLE training_jobs ADD COLUMN container_id TEXT NOT NULL DEFAULT '';
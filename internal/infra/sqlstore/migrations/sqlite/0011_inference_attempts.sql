@@ -0,0 +1,2 @@
+ALTER TABLE inference_jobs ADD COLUMN failure_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE inference_jobs ADD COLUMN attempts TEXT NOT NULL DEFAULT '[]';
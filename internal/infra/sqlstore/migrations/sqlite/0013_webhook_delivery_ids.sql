@@ -0,0 +1,21 @@
+CREATE TABLE webhook_deliveries_v2 (
+    id               TEXT PRIMARY KEY,
+    event_id         BIGINT NOT NULL,
+    job_id           TEXT NOT NULL,
+    url              TEXT NOT NULL,
+    event            TEXT NOT NULL,
+    status           TEXT NOT NULL,
+    attempts         TEXT NOT NULL DEFAULT '[]',
+    next_attempt_at  TIMESTAMP NOT NULL,
+    created_at       TIMESTAMP NOT NULL
+);
+
+INSERT INTO webhook_deliveries_v2
+SELECT CAST(id AS TEXT), id, job_id, url, event, status, attempts, next_attempt_at, created_at
+FROM webhook_deliveries;
+
+DROP TABLE webhook_deliveries;
+ALTER TABLE webhook_deliveries_v2 RENAME TO webhook_deliveries;
+
+CREATE INDEX webhook_deliveries_job_id ON webhook_deliveries (job_id);
+CREATE INDEX webhook_deliveries_status ON webhook_deliveries (status);
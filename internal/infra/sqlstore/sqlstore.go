@@ -0,0 +1,52 @@
+// Package sqlstore implements the job repositories on top of database/sql.
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Open connects to the database selected by driver and applies any pending
+// schema migrations before returning the handle.
+func Open(driver string, dsn string) (*sql.DB, error) {
+	var sqlDriver string
+	switch driver {
+	case DriverSQLite:
+		sqlDriver = "sqlite3"
+	case DriverPostgres:
+		sqlDriver = "postgres"
+	default:
+		return nil, fmt.Errorf("sqlstore - Open - unsupported driver %q", driver)
+	}
+
+	db, err := sql.Open(sqlDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sqlstore - Open - sql.Open: %w", err)
+	}
+
+	if driver == DriverSQLite {
+		// SQLite only allows a single writer, serialize access instead of
+		// surfacing "database is locked" errors to the callers.
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlstore - Open - db.Ping: %w", err)
+	}
+
+	if err := migrate(db, driver); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlstore - Open - migrate: %w", err)
+	}
+
+	return db, nil
+}
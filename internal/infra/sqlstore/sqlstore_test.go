@@ -0,0 +1,76 @@
+package sqlstore_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/sqlstore"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// _at is a fixed time without a monotonic reading, it survives a round trip
+// through the database unchanged.
+var _at = time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+// openSQLite opens a migrated sqlite database in a temporary directory.
+func openSQLite(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sqlstore.Open(sqlstore.DriverSQLite, path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func newDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	return openSQLite(t, filepath.Join(t.TempDir(), "jobs.db"))
+}
+
+// runningJob returns a job that went through provisioning into running.
+func runningJob(id string) entity.GenericJob {
+	return entity.GenericJob{
+		ID:     id,
+		Name:   "train",
+		Status: entity.JobStateRunning,
+		Transitions: []entity.JobTransition{
+			{From: "", To: entity.JobStateQueued, At: _at},
+			{From: entity.JobStateQueued, To: entity.JobStateProvisioning, At: _at.Add(time.Second)},
+			{From: entity.JobStateProvisioning, To: entity.JobStateRunning, At: _at.Add(time.Minute)},
+		},
+		Priority: entity.PriorityHigh,
+	}
+}
+
+func TestOpenMigratesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	openSQLite(t, path).Close()
+
+	// Opening the database again must skip the applied migrations.
+	db := openSQLite(t, path)
+
+	files, err := os.ReadDir("migrations/sqlite")
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+
+	var applied int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatalf("count migrations: %v", err)
+	}
+	if applied != len(files) {
+		t.Errorf("%d migrations applied, want %d", applied, len(files))
+	}
+}
+
+func TestOpenUnsupportedDriver(t *testing.T) {
+	if _, err := sqlstore.Open("mysql", ""); err == nil {
+		t.Error("Open of mysql succeeded")
+	}
+}
@@ -0,0 +1,321 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// backend records which active list a job belongs to, queued jobs have not
+// been placed on a backend yet.
+const (
+	backendQueue  = "queue"
+	backendDocker = "docker"
+	backendTwcc   = "twcc"
+)
+
+const trainingJobColumns = `id, name, status, transitions, priority, docker_image_name, container_id, twcc_job_id, placement_policy, enqueued_at,
+	exit_code, oom_killed, failure_reason, spec, phase, pull_progress`
+
+type trainingJobRow struct {
+	job             entity.GenericJob
+	spec            entity.JobSpec
+	containerID     string
+	twccJobId       string
+	placementPolicy string
+	enqueuedAt      sql.NullTime
+}
+
+func scanTrainingJob(s scanner) (trainingJobRow, error) {
+	var (
+		row          trainingJobRow
+		transitions  string
+		exitCode     sql.NullInt64
+		image        string
+		spec         string
+		pullProgress string
+	)
+
+	err := s.Scan(&row.job.ID, &row.job.Name, &row.job.Status, &transitions, &row.job.Priority,
+		&image, &row.containerID, &row.twccJobId, &row.placementPolicy, &row.enqueuedAt,
+		&exitCode, &row.job.OOMKilled, &row.job.FailureReason, &spec, &row.job.Phase, &pullProgress)
+	if err != nil {
+		return trainingJobRow{}, err
+	}
+
+	if exitCode.Valid {
+		row.job.ExitCode = &exitCode.Int64
+	}
+
+	if err := fromJSONColumn(transitions, &row.job.Transitions); err != nil {
+		return trainingJobRow{}, err
+	}
+
+	if err := fromJSONColumn(spec, &row.spec); err != nil {
+		return trainingJobRow{}, err
+	}
+
+	if err := fromJSONColumn(pullProgress, &row.job.PullProgress); err != nil {
+		return trainingJobRow{}, err
+	}
+
+	// Jobs stored before the spec column only know their image.
+	if row.spec.Image == "" {
+		row.spec.Image = image
+	}
+
+	return row, nil
+}
+
+type TrainingJobsStore struct {
+	db *sql.DB
+}
+
+func NewTrainingJobsStore(db *sql.DB) *TrainingJobsStore {
+	return &TrainingJobsStore{db: db}
+}
+
+func (r *TrainingJobsStore) push(backend string, row trainingJobRow) error {
+	transitions, err := jsonColumn(row.job.Transitions)
+	if err != nil {
+		return err
+	}
+
+	spec, err := jsonColumn(row.spec)
+	if err != nil {
+		return err
+	}
+
+	pullProgress, err := jsonColumn(row.job.PullProgress)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO training_jobs (id, name, status, transitions, priority, backend,
+			docker_image_name, container_id, twcc_job_id, placement_policy, enqueued_at,
+			exit_code, oom_killed, failure_reason, spec, phase, pull_progress, finished, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, FALSE, $18)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			status = excluded.status,
+			transitions = excluded.transitions,
+			priority = excluded.priority,
+			backend = excluded.backend,
+			docker_image_name = excluded.docker_image_name,
+			container_id = excluded.container_id,
+			twcc_job_id = excluded.twcc_job_id,
+			placement_policy = excluded.placement_policy,
+			enqueued_at = excluded.enqueued_at,
+			exit_code = excluded.exit_code,
+			oom_killed = excluded.oom_killed,
+			failure_reason = excluded.failure_reason,
+			spec = excluded.spec,
+			phase = excluded.phase,
+			pull_progress = excluded.pull_progress,
+			finished = FALSE`,
+		row.job.ID, row.job.Name, row.job.Status, transitions, row.job.Priority, backend,
+		row.spec.Image, row.containerID, row.twccJobId, row.placementPolicy, row.enqueuedAt,
+		row.job.ExitCode, row.job.OOMKilled, row.job.FailureReason, spec,
+		row.job.Phase, pullProgress, time.Now().UTC())
+
+	return err
+}
+
+func (r *TrainingJobsStore) PushQueuedJob(j entity.QueuedJob) error {
+	row := trainingJobRow{
+		job:             j.Job,
+		spec:            j.Spec,
+		twccJobId:       j.TwccJobId,
+		placementPolicy: j.PlacementPolicy,
+		enqueuedAt:      sql.NullTime{Time: j.EnqueuedAt.UTC(), Valid: true},
+	}
+
+	if err := r.push(backendQueue, row); err != nil {
+		return fmt.Errorf("TrainingJobsStore - PushQueuedJob - r.push: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TrainingJobsStore) PushTwccJob(j entity.TwccJob) error {
+	if err := r.push(backendTwcc, trainingJobRow{job: j.Job, spec: j.Spec, twccJobId: j.TwccJobId}); err != nil {
+		return fmt.Errorf("TrainingJobsStore - PushTwccJob - r.push: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TrainingJobsStore) PushContainerJob(j entity.ContainerJob) error {
+	row := trainingJobRow{job: j.Job, spec: j.Spec, containerID: j.ContainerID, placementPolicy: j.PlacementPolicy}
+	if err := r.push(backendDocker, row); err != nil {
+		return fmt.Errorf("TrainingJobsStore - PushContainerJob - r.push: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TrainingJobsStore) GetJob(id string) (entity.GenericJob, error) {
+	row, err := scanTrainingJob(r.db.QueryRow(`SELECT `+trainingJobColumns+` FROM training_jobs WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return entity.GenericJob{}, fmt.Errorf("TrainingJobsStore - GetJob - job not found")
+	}
+	if err != nil {
+		return entity.GenericJob{}, fmt.Errorf("TrainingJobsStore - GetJob - scanTrainingJob: %w", err)
+	}
+
+	return row.job, nil
+}
+
+func (r *TrainingJobsStore) list(where string, orderBy string, args ...any) ([]trainingJobRow, error) {
+	rows, err := r.db.Query(`SELECT `+trainingJobColumns+` FROM training_jobs WHERE `+where+` ORDER BY `+orderBy, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]trainingJobRow, 0, 64)
+	for rows.Next() {
+		row, err := scanTrainingJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, row)
+	}
+
+	return jobs, rows.Err()
+}
+
+// GetQueuedJobList returns the queued jobs in the order they were enqueued.
+func (r *TrainingJobsStore) GetQueuedJobList() ([]entity.QueuedJob, error) {
+	rows, err := r.list(`backend = $1 AND finished = FALSE`, `enqueued_at, created_at`, backendQueue)
+	if err != nil {
+		return nil, fmt.Errorf("TrainingJobsStore - GetQueuedJobList - r.list: %w", err)
+	}
+
+	jobs := make([]entity.QueuedJob, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, entity.QueuedJob{
+			Job:             row.job,
+			Spec:            row.spec,
+			TwccJobId:       row.twccJobId,
+			PlacementPolicy: row.placementPolicy,
+			EnqueuedAt:      row.enqueuedAt.Time,
+		})
+	}
+
+	return jobs, nil
+}
+
+func (r *TrainingJobsStore) GetTwccJobList() ([]entity.TwccJob, error) {
+	rows, err := r.list(`backend = $1 AND finished = FALSE`, `created_at`, backendTwcc)
+	if err != nil {
+		return nil, fmt.Errorf("TrainingJobsStore - GetTwccJobList - r.list: %w", err)
+	}
+
+	jobs := make([]entity.TwccJob, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, entity.TwccJob{Job: row.job, Spec: row.spec, TwccJobId: row.twccJobId})
+	}
+
+	return jobs, nil
+}
+
+func (r *TrainingJobsStore) GetContainerJobList() ([]entity.ContainerJob, error) {
+	rows, err := r.list(`backend = $1 AND finished = FALSE`, `created_at`, backendDocker)
+	if err != nil {
+		return nil, fmt.Errorf("TrainingJobsStore - GetContainerJobList - r.list: %w", err)
+	}
+
+	jobs := make([]entity.ContainerJob, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, entity.ContainerJob{Job: row.job, Spec: row.spec, ContainerID: row.containerID, PlacementPolicy: row.placementPolicy})
+	}
+
+	return jobs, nil
+}
+
+func (r *TrainingJobsStore) GetHistoryJobList() ([]entity.GenericJob, error) {
+	rows, err := r.list(`finished = TRUE`, `created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("TrainingJobsStore - GetHistoryJobList - r.list: %w", err)
+	}
+
+	jobs := make([]entity.GenericJob, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, row.job)
+	}
+
+	return jobs, nil
+}
+
+// storeHistory moves an active job of the given backend into the history.
+func (r *TrainingJobsStore) storeHistory(id string, backend string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE training_jobs SET finished = TRUE
+		WHERE id = $1 AND backend = $2 AND finished = FALSE`, id, backend)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (r *TrainingJobsStore) DeleteQueuedJob(id string) error {
+	ok, err := r.storeHistory(id, backendQueue)
+	if err != nil {
+		return fmt.Errorf("TrainingJobsStore - DeleteQueuedJob - r.storeHistory: %w", err)
+	}
+
+	if !ok {
+		return fmt.Errorf("TrainingJobsStore - DeleteQueuedJob - job not found")
+	}
+
+	return nil
+}
+
+func (r *TrainingJobsStore) DeleteTwccJob(id string) error {
+	ok, err := r.storeHistory(id, backendTwcc)
+	if err != nil {
+		return fmt.Errorf("TrainingJobsStore - DeleteTwccJob - r.storeHistory: %w", err)
+	}
+
+	if !ok {
+		return fmt.Errorf("TrainingJobsStore - DeleteTwccJob - job not found")
+	}
+
+	return nil
+}
+
+func (r *TrainingJobsStore) DeleteContainerJob(id string) error {
+	ok, err := r.storeHistory(id, backendDocker)
+	if err != nil {
+		return fmt.Errorf("TrainingJobsStore - DeleteContainerJob - r.storeHistory: %w", err)
+	}
+
+	if !ok {
+		return fmt.Errorf("TrainingJobsStore - DeleteContainerJob - job not found")
+	}
+
+	return nil
+}
+
+func (r *TrainingJobsStore) GetContainerJob(id string) (entity.ContainerJob, error) {
+	row, err := scanTrainingJob(r.db.QueryRow(`SELECT `+trainingJobColumns+` FROM training_jobs
+		WHERE id = $1 AND backend = $2`, id, backendDocker))
+	if err == sql.ErrNoRows {
+		return entity.ContainerJob{}, fmt.Errorf("TrainingJobsStore - GetContainerJob - job not found")
+	}
+	if err != nil {
+		return entity.ContainerJob{}, fmt.Errorf("TrainingJobsStore - GetContainerJob - scanTrainingJob: %w", err)
+	}
+
+	return entity.ContainerJob{Job: row.job, Spec: row.spec, ContainerID: row.containerID, PlacementPolicy: row.placementPolicy}, nil
+}
@@ -0,0 +1,112 @@
+package sqlstore_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/sqlstore"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+func TestTrainingJobsStoreRoundTrip(t *testing.T) {
+	r := sqlstore.NewTrainingJobsStore(newDB(t))
+
+	spec := entity.JobSpec{
+		Image:   "pytorch-24.08-py3:latest",
+		Command: []string{"python"},
+		Args:    []string{"train.py", "--epochs", "3"},
+		Env:     map[string]string{"SEED": "1"},
+		Mounts:  []entity.Mount{{Type: entity.MountType("bind"), Source: "/data", Target: "/data", ReadOnly: true}},
+		Labels:  map[string]string{"team": "ml"},
+	}
+	job := runningJob("job-1")
+	job.Status = entity.JobStateQueued
+	job.Transitions = job.Transitions[:1]
+
+	queued := entity.QueuedJob{Job: job, Spec: spec, PlacementPolicy: "local-first", EnqueuedAt: _at}
+	if err := r.PushQueuedJob(queued); err != nil {
+		t.Fatalf("PushQueuedJob: %v", err)
+	}
+
+	queue, err := r.GetQueuedJobList()
+	if err != nil {
+		t.Fatalf("GetQueuedJobList: %v", err)
+	}
+	if len(queue) != 1 || !reflect.DeepEqual(queue[0], queued) {
+		t.Fatalf("queue %+v, want %+v", queue, queued)
+	}
+
+	// Placing the job moves the same row onto docker.
+	exitCode := int64(137)
+	running := entity.ContainerJob{Job: runningJob("job-1"), Spec: spec, ContainerID: "4c01db0b339c", PlacementPolicy: "local-first"}
+	running.Job.Phase = entity.JobPhase("pulling image")
+	running.Job.PullProgress = &entity.ImagePullProgress{Layers: 5, LayersDone: 3, Current: 100, Total: 500}
+	running.Job.ExitCode = &exitCode
+	running.Job.OOMKilled = true
+	if err := r.PushContainerJob(running); err != nil {
+		t.Fatalf("PushContainerJob: %v", err)
+	}
+
+	if queue, _ := r.GetQueuedJobList(); len(queue) != 0 {
+		t.Errorf("%d queued jobs left after the job was placed", len(queue))
+	}
+	containerJobs, err := r.GetContainerJobList()
+	if err != nil {
+		t.Fatalf("GetContainerJobList: %v", err)
+	}
+	if len(containerJobs) != 1 || !reflect.DeepEqual(containerJobs[0], running) {
+		t.Fatalf("container jobs %+v, want %+v", containerJobs, running)
+	}
+	if j, err := r.GetContainerJob("job-1"); err != nil || !reflect.DeepEqual(j, running) {
+		t.Errorf("GetContainerJob: %+v, %v", j, err)
+	}
+
+	if err := r.DeleteContainerJob("job-1"); err != nil {
+		t.Fatalf("DeleteContainerJob: %v", err)
+	}
+	if err := r.DeleteContainerJob("job-1"); err == nil {
+		t.Error("DeleteContainerJob of a finished job succeeded")
+	}
+
+	history, err := r.GetHistoryJobList()
+	if err != nil {
+		t.Fatalf("GetHistoryJobList: %v", err)
+	}
+	if len(history) != 1 || !reflect.DeepEqual(history[0], running.Job) {
+		t.Errorf("history %+v, want %+v", history, running.Job)
+	}
+	if j, err := r.GetJob("job-1"); err != nil || j.ID != "job-1" {
+		t.Errorf("GetJob of a finished job: %+v, %v", j, err)
+	}
+}
+
+func TestTrainingJobsStoreQueueOrder(t *testing.T) {
+	r := sqlstore.NewTrainingJobsStore(newDB(t))
+
+	for i, id := range []string{"b", "a", "c"} {
+		job := entity.NewGenericJob(id, id)
+		err := r.PushQueuedJob(entity.QueuedJob{Job: job, EnqueuedAt: _at.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatalf("PushQueuedJob %s: %v", id, err)
+		}
+	}
+
+	// Requeueing a job moves it to the back of the queue.
+	if err := r.PushQueuedJob(entity.QueuedJob{Job: entity.NewGenericJob("b", "b"), EnqueuedAt: _at.Add(time.Minute)}); err != nil {
+		t.Fatalf("PushQueuedJob b again: %v", err)
+	}
+
+	queue, err := r.GetQueuedJobList()
+	if err != nil {
+		t.Fatalf("GetQueuedJobList: %v", err)
+	}
+
+	var ids []string
+	for _, j := range queue {
+		ids = append(ids, j.Job.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "c", "b"}) {
+		t.Errorf("queue %v, want [a c b]", ids)
+	}
+}
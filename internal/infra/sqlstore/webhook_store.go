@@ -0,0 +1,146 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+const webhookDeliveryColumns = `id, event_id, job_id, url, event, status, attempts, next_attempt_at, created_at`
+
+func scanWebhookDelivery(s scanner) (entity.WebhookDelivery, error) {
+	var (
+		d        entity.WebhookDelivery
+		eventID  int64
+		event    string
+		attempts string
+	)
+
+	err := s.Scan(&d.ID, &eventID, &d.JobID, &d.URL, &event, &d.Status, &attempts, &d.NextAttemptAt, &d.CreatedAt)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+	d.EventID = uint64(eventID)
+
+	if err := fromJSONColumn(event, &d.Event); err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	if err := fromJSONColumn(attempts, &d.Attempts); err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	return d, nil
+}
+
+type WebhooksStore struct {
+	db *sql.DB
+}
+
+func NewWebhooksStore(db *sql.DB) *WebhooksStore {
+	return &WebhooksStore{db: db}
+}
+
+func (r *WebhooksStore) StoreWebhook(w entity.Webhook) error {
+	_, err := r.db.Exec(`
+		INSERT INTO webhooks (job_id, kind, url, secret, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (job_id) DO UPDATE SET
+			kind = excluded.kind,
+			url = excluded.url,
+			secret = excluded.secret`,
+		w.JobID, w.Kind, w.URL, w.Secret, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("WebhooksStore - StoreWebhook - r.db.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhooksStore) GetWebhook(jobID string) (entity.Webhook, error) {
+	var w entity.Webhook
+	err := r.db.QueryRow(`SELECT job_id, kind, url, secret FROM webhooks WHERE job_id = $1`, jobID).
+		Scan(&w.JobID, &w.Kind, &w.URL, &w.Secret)
+	if err == sql.ErrNoRows {
+		return entity.Webhook{}, fmt.Errorf("WebhooksStore - GetWebhook - %w", ports.ErrWebhookNotFound)
+	}
+	if err != nil {
+		return entity.Webhook{}, fmt.Errorf("WebhooksStore - GetWebhook - r.db.QueryRow: %w", err)
+	}
+
+	return w, nil
+}
+
+func (r *WebhooksStore) DeleteWebhook(jobID string) error {
+	if _, err := r.db.Exec(`DELETE FROM webhooks WHERE job_id = $1`, jobID); err != nil {
+		return fmt.Errorf("WebhooksStore - DeleteWebhook - r.db.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhooksStore) StoreWebhookDelivery(d entity.WebhookDelivery) error {
+	event, err := jsonColumn(d.Event)
+	if err != nil {
+		return fmt.Errorf("WebhooksStore - StoreWebhookDelivery - jsonColumn: %w", err)
+	}
+
+	attempts, err := jsonColumn(d.Attempts)
+	if err != nil {
+		return fmt.Errorf("WebhooksStore - StoreWebhookDelivery - jsonColumn: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			status = excluded.status,
+			attempts = excluded.attempts,
+			next_attempt_at = excluded.next_attempt_at`,
+		d.ID, int64(d.EventID), d.JobID, d.URL, event, d.Status, attempts, d.NextAttemptAt.UTC(), d.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("WebhooksStore - StoreWebhookDelivery - r.db.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhooksStore) queryWebhookDeliveries(query string, args ...any) ([]entity.WebhookDelivery, error) {
+	rows, err := r.db.Query(`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]entity.WebhookDelivery, 0, 8)
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhooksStore) GetWebhookDeliveries(jobID string) ([]entity.WebhookDelivery, error) {
+	deliveries, err := r.queryWebhookDeliveries(`WHERE job_id = $1 ORDER BY created_at, event_id`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("WebhooksStore - GetWebhookDeliveries - r.queryWebhookDeliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhooksStore) GetPendingWebhookDeliveries() ([]entity.WebhookDelivery, error) {
+	deliveries, err := r.queryWebhookDeliveries(`WHERE status = $1 ORDER BY created_at, event_id`, entity.WebhookDeliveryPending)
+	if err != nil {
+		return nil, fmt.Errorf("WebhooksStore - GetPendingWebhookDeliveries - r.queryWebhookDeliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,89 @@
+package sqlstore_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/sqlstore"
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+func TestWebhooksStoreWebhook(t *testing.T) {
+	r := sqlstore.NewWebhooksStore(newDB(t))
+
+	hook := entity.Webhook{JobID: "job-1", Kind: entity.JobKindTraining, URL: "https://ci.example.com/a", Secret: "s3cret"}
+	if err := r.StoreWebhook(hook); err != nil {
+		t.Fatalf("StoreWebhook: %v", err)
+	}
+
+	// Registering the webhook again replaces it.
+	hook.URL = "https://ci.example.com/b"
+	if err := r.StoreWebhook(hook); err != nil {
+		t.Fatalf("StoreWebhook again: %v", err)
+	}
+	if got, err := r.GetWebhook("job-1"); err != nil || got != hook {
+		t.Errorf("GetWebhook: %+v, %v, want %+v", got, err, hook)
+	}
+
+	if err := r.DeleteWebhook("job-1"); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if _, err := r.GetWebhook("job-1"); !errors.Is(err, ports.ErrWebhookNotFound) {
+		t.Errorf("GetWebhook of a deleted webhook: %v, want ErrWebhookNotFound", err)
+	}
+}
+
+func TestWebhooksStoreDeliveries(t *testing.T) {
+	r := sqlstore.NewWebhooksStore(newDB(t))
+
+	event := entity.JobEvent{ID: 1698840000000, Kind: entity.JobKindTraining, JobID: "job-1",
+		From: entity.JobStateRunning, To: entity.JobStateFailed, FailureReason: "oom", At: _at}
+	first := entity.WebhookDelivery{
+		ID:            "delivery-1",
+		EventID:       event.ID,
+		JobID:         "job-1",
+		URL:           "https://ci.example.com/a",
+		Event:         event,
+		Status:        entity.WebhookDeliveryPending,
+		Attempts:      []entity.WebhookAttempt{},
+		NextAttemptAt: _at,
+		CreatedAt:     _at,
+	}
+	// An event id reused after a restart must not overwrite the first
+	// delivery.
+	second := first
+	second.ID = "delivery-2"
+	second.CreatedAt = _at.Add(time.Second)
+
+	for _, d := range []entity.WebhookDelivery{first, second} {
+		if err := r.StoreWebhookDelivery(d); err != nil {
+			t.Fatalf("StoreWebhookDelivery %s: %v", d.ID, err)
+		}
+	}
+
+	first.Status = entity.WebhookDeliveryDelivered
+	first.Attempts = []entity.WebhookAttempt{{At: _at, StatusCode: 500, Error: "bad gateway"}, {At: _at.Add(5 * time.Second), StatusCode: 200}}
+	first.NextAttemptAt = _at.Add(5 * time.Second)
+	if err := r.StoreWebhookDelivery(first); err != nil {
+		t.Fatalf("StoreWebhookDelivery again: %v", err)
+	}
+
+	deliveries, err := r.GetWebhookDeliveries("job-1")
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveries: %v", err)
+	}
+	if want := []entity.WebhookDelivery{first, second}; !reflect.DeepEqual(deliveries, want) {
+		t.Errorf("deliveries %+v, want %+v", deliveries, want)
+	}
+
+	pending, err := r.GetPendingWebhookDeliveries()
+	if err != nil {
+		t.Fatalf("GetPendingWebhookDeliveries: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != second.ID {
+		t.Errorf("pending deliveries %+v, want %s", pending, second.ID)
+	}
+}
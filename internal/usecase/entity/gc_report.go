@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// GCReport is the outcome of a garbage collection. Removed lists the
+// resources that were removed, or would have been in a dry run, Errors the
+// resources that could not be listed or removed.
+type GCReport struct {
+	DryRun     bool      `json:"dryRun" example:"false"`
+	StartedAt  time.Time `json:"startedAt" example:"2023-11-01T12:00:00Z"`
+	FinishedAt time.Time `json:"finishedAt" example:"2023-11-01T12:00:02Z"`
+	Removed    []Orphan  `json:"removed"`
+	Errors     []string  `json:"errors"`
+}
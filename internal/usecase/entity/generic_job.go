@@ -1,7 +1,33 @@
 package entity
 
+import "time"
+
 type GenericJob struct {
-	ID     string `json:"jobId"       example:"12345"`
-	Name   string `json:"jobName"       example:"name"`
-	Status string `json:"jobStatus"       example:"jobStatus"`
+	ID          string          `json:"jobId"       example:"12345"`
+	Name        string          `json:"jobName"       example:"name"`
+	Status      JobState        `json:"jobStatus"       example:"running"`
+	Transitions []JobTransition `json:"transitions"`
+	Priority    PriorityClass   `json:"priorityClass,omitempty" example:"normal"`
+	// Phase and PullProgress report the progress of a provisioning job.
+	Phase        JobPhase           `json:"phase,omitempty" example:"pulling image"`
+	PullProgress *ImagePullProgress `json:"pullProgress,omitempty"`
+	// ExitCode and OOMKilled are set once the container of a docker job exited.
+	ExitCode  *int64 `json:"exitCode,omitempty" example:"137"`
+	OOMKilled bool   `json:"oomKilled,omitempty" example:"true"`
+	// FailureReason explains why a job ended up failed.
+	FailureReason string `json:"failureReason,omitempty" example:"container was killed by the OOM killer"`
+	// QueuePosition is the 1-based position of a queued job, it is computed
+	// on read and zero for jobs that are not waiting in the queue.
+	QueuePosition int `json:"queuePosition,omitempty" example:"1"`
+}
+
+// EnteredAt returns when the job first entered the given state.
+func (j GenericJob) EnteredAt(state JobState) (time.Time, bool) {
+	for _, t := range j.Transitions {
+		if t.To == state {
+			return t.At, true
+		}
+	}
+
+	return time.Time{}, false
 }
@@ -1,7 +1,36 @@
 package entity
 
+import "time"
+
 type InferenceJob struct {
-	Job        GenericJob `json:"job"`
-	TwccCCSId  string     `json:"jobId" example:"12345"`
-	EntryPoint string     `json:"entryPoint" example:"12345"`
+	Job        GenericJob    `json:"job"`
+	Spec       InferenceSpec `json:"spec"`
+	TwccCCSId  string        `json:"jobId" example:"12345"`
+	EntryPoint string        `json:"entryPoint" example:"12345"`
+	// TTL is the lease of the job, its CCS is torn down at ExpiresAt unless
+	// the lease is renewed. The lease starts once the job runs.
+	TTL       time.Duration `json:"ttl" swaggertype:"integer" example:"1800000000000"`
+	ExpiresAt time.Time     `json:"expiresAt" example:"2023-11-01T12:30:00Z"`
+	// Attempts lists the failed attempts to provision the CCS of the job.
+	Attempts []ProvisioningAttempt `json:"attempts"`
+	// ProxyRequests counts the requests proxied to the job since the
+	// server started, it is not stored.
+	ProxyRequests uint64 `json:"proxyRequests" example:"42"`
+}
+
+// ProvisioningAttempt is a failed attempt to provision the CCS of a job.
+// Step names the step that failed with Reason. The CCS of the attempt was
+// deleted again, unless RollbackError says why that failed.
+type ProvisioningAttempt struct {
+	StartedAt     time.Time `json:"startedAt" example:"2023-11-01T12:00:00Z"`
+	FailedAt      time.Time `json:"failedAt" example:"2023-11-01T12:05:00Z"`
+	Step          string    `json:"step" example:"wait for entry point"`
+	TwccCCSId     string    `json:"ccsId,omitempty" example:"12345"`
+	Reason        string    `json:"reason" example:"inference job did not become ready: ccs 12345 timed out in status Pending"`
+	RollbackError string    `json:"rollbackError,omitempty"`
+}
+
+// Expired reports whether the lease of a job with a TTL ran out.
+func (j InferenceJob) Expired(now time.Time) bool {
+	return !j.ExpiresAt.IsZero() && !now.Before(j.ExpiresAt)
 }
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"fmt"
+	"path"
+)
+
+// InferenceMount mounts a path of the twcc storage, gpfs01 (work) or gpfs02
+// (home), into the inference container.
+type InferenceMount struct {
+	Storage string `json:"storage" example:"gpfs01" enums:"gpfs01,gpfs02"`
+	Path    string `json:"path" example:"/work/yjack0000"`
+}
+
+// InferenceSpec describes the CCS an inference job runs in. Fields left empty
+// are filled from the configured defaults.
+type InferenceSpec struct {
+	Image   string `json:"image,omitempty" example:"tensorflow-23.08-tf2-py3:latest"`
+	Flavor  string `json:"flavor,omitempty" example:"1 GPU + 04 cores + 090GB memory"`
+	Replica int    `json:"replica,omitempty" example:"1"`
+	// Ports are the container ports exposed on the public IP, the first one
+	// serves the entry point.
+	Ports   []int            `json:"ports,omitempty" example:"5000"`
+	Mounts  []InferenceMount `json:"mounts,omitempty"`
+	Project string           `json:"project,omitempty" example:"65662"`
+}
+
+// WithDefaults returns the spec with its empty fields taken from defaults.
+func (s InferenceSpec) WithDefaults(defaults InferenceSpec) InferenceSpec {
+	if s.Image == "" {
+		s.Image = defaults.Image
+	}
+	if s.Flavor == "" {
+		s.Flavor = defaults.Flavor
+	}
+	if s.Replica == 0 {
+		s.Replica = defaults.Replica
+	}
+	if len(s.Ports) == 0 {
+		s.Ports = defaults.Ports
+	}
+	if len(s.Mounts) == 0 {
+		s.Mounts = defaults.Mounts
+	}
+	if s.Project == "" {
+		s.Project = defaults.Project
+	}
+
+	return s
+}
+
+// Validate reports the first problem of the spec, wrapped in
+// ErrInvalidJobSpec.
+func (s InferenceSpec) Validate() error {
+	if s.Replica < 0 {
+		return fmt.Errorf("%w: replica must not be negative", ErrInvalidJobSpec)
+	}
+
+	for _, p := range s.Ports {
+		if p < 1 || p > 65535 {
+			return fmt.Errorf("%w: port %d is out of range", ErrInvalidJobSpec, p)
+		}
+	}
+
+	seen := make(map[string]bool, len(s.Mounts))
+	for _, m := range s.Mounts {
+		if m.Storage != "gpfs01" && m.Storage != "gpfs02" {
+			return fmt.Errorf("%w: unknown storage %q", ErrInvalidJobSpec, m.Storage)
+		}
+
+		if seen[m.Storage] {
+			return fmt.Errorf("%w: storage %q is mounted twice", ErrInvalidJobSpec, m.Storage)
+		}
+		seen[m.Storage] = true
+
+		if !path.IsAbs(m.Path) {
+			return fmt.Errorf("%w: mount path %q is not absolute", ErrInvalidJobSpec, m.Path)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type JobKind string
+
+const (
+	JobKindTraining  JobKind = "training"
+	JobKindInference JobKind = "inference"
+)
+
+// JobEvent is a state transition of a job. IDs increase with every event,
+// also across restarts.
+type JobEvent struct {
+	ID    uint64   `json:"id" example:"1698840000000"`
+	Kind  JobKind  `json:"kind" example:"training"`
+	JobID string   `json:"jobId" example:"0b9b7a43-6f3d-4c1e-9d67-1f0e3a1c2b4d"`
+	From  JobState `json:"from" example:"running"`
+	To    JobState `json:"to" example:"succeeded"`
+	// FailureReason is set for transitions into a failed state.
+	FailureReason string    `json:"failureReason,omitempty"`
+	At            time.Time `json:"at" example:"2023-11-01T12:00:00Z"`
+}
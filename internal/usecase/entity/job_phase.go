@@ -0,0 +1,18 @@
+package entity
+
+// JobPhase details what a provisioning job is busy with.
+type JobPhase string
+
+const (
+	JobPhasePullingImage      JobPhase = "pulling image"
+	JobPhaseCreatingContainer JobPhase = "creating container"
+)
+
+// ImagePullProgress sums up the layers of an image pull, sizes are in bytes.
+// Layers that already exist locally count as done without any bytes.
+type ImagePullProgress struct {
+	Layers     int   `json:"layers" example:"5"`
+	LayersDone int   `json:"layersDone" example:"3"`
+	Current    int64 `json:"current" example:"104857600"`
+	Total      int64 `json:"total" example:"524288000"`
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+var ErrInvalidJobSpec = errors.New("invalid job spec")
+
+// MountType is how a Mount is backed.
+type MountType string
+
+const (
+	MountTypeBind   MountType = "bind"
+	MountTypeVolume MountType = "volume"
+)
+
+// Mount makes a host path or a named volume available inside the job.
+type Mount struct {
+	Type     MountType `json:"type" example:"bind" enums:"bind,volume"`
+	Source   string    `json:"source" example:"/data/datasets"`
+	Target   string    `json:"target" example:"/datasets"`
+	ReadOnly bool      `json:"readOnly,omitempty"`
+}
+
+// JobSpec describes what a training job runs. Command replaces the entrypoint
+// of the image and Args its default arguments, both are kept if left empty.
+type JobSpec struct {
+	Image      string            `json:"image" example:"ubuntu:latest"`
+	Command    []string          `json:"command,omitempty" example:"python"`
+	Args       []string          `json:"args,omitempty" example:"train.py"`
+	Env        map[string]string `json:"env,omitempty"`
+	Mounts     []Mount           `json:"mounts,omitempty"`
+	WorkingDir string            `json:"workingDir,omitempty" example:"/workspace"`
+	// CPUs limits the job to a fraction of the host CPUs, 0 means no limit.
+	CPUs float64 `json:"cpus,omitempty" example:"1.5"`
+	// Memory and ShmSize are in bytes, 0 keeps the docker defaults.
+	Memory  int64             `json:"memory,omitempty" example:"4294967296"`
+	ShmSize int64             `json:"shmSize,omitempty" example:"1073741824"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	// Flavor and Project select the resources and the project of a twcc job,
+	// the configured defaults are used if left empty.
+	Flavor  string `json:"flavor,omitempty" example:"1 GPU + 04 cores + 090GB memory"`
+	Project string `json:"project,omitempty" example:"65662"`
+	// RegistryCredential names the configured registry credentials used to
+	// pull Image, by default they are picked by the registry of the image.
+	RegistryCredential string `json:"registryCredential,omitempty" example:"ghcr"`
+}
+
+// Validate reports the first problem of the spec, wrapped in
+// ErrInvalidJobSpec.
+func (s JobSpec) Validate() error {
+	if s.CPUs < 0 || s.Memory < 0 || s.ShmSize < 0 {
+		return fmt.Errorf("%w: resource limits must not be negative", ErrInvalidJobSpec)
+	}
+
+	if s.WorkingDir != "" && !path.IsAbs(s.WorkingDir) {
+		return fmt.Errorf("%w: working directory %q is not absolute", ErrInvalidJobSpec, s.WorkingDir)
+	}
+
+	for _, m := range s.Mounts {
+		if m.Type != MountTypeBind && m.Type != MountTypeVolume {
+			return fmt.Errorf("%w: unknown mount type %q", ErrInvalidJobSpec, m.Type)
+		}
+
+		if m.Source == "" {
+			return fmt.Errorf("%w: mount source is required", ErrInvalidJobSpec)
+		}
+
+		if m.Type == MountTypeBind && !path.IsAbs(m.Source) {
+			return fmt.Errorf("%w: bind mount source %q is not absolute", ErrInvalidJobSpec, m.Source)
+		}
+
+		if !path.IsAbs(m.Target) {
+			return fmt.Errorf("%w: mount target %q is not absolute", ErrInvalidJobSpec, m.Target)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type JobState string
+
+const (
+	JobStatePending      JobState = "pending"
+	JobStateQueued       JobState = "queued"
+	JobStateProvisioning JobState = "provisioning"
+	JobStateRunning      JobState = "running"
+	JobStateSucceeded    JobState = "succeeded"
+	JobStateFailed       JobState = "failed"
+	JobStateCancelled    JobState = "cancelled"
+	JobStateExpired      JobState = "expired"
+	// JobStateLost is a job whose container or twcc resource vanished while
+	// the service was down.
+	JobStateLost JobState = "lost"
+)
+
+var ErrInvalidTransition = errors.New("invalid job state transition")
+
+// jobStateTransitions lists the states each state is allowed to move to.
+// Terminal states have no outgoing transitions. Running jobs move back into
+// the queue when they are preempted.
+// Jobs get lost when their resource is gone after a restart.
+var jobStateTransitions = map[JobState][]JobState{
+	JobStatePending:      {JobStateQueued, JobStateProvisioning, JobStateFailed, JobStateCancelled},
+	JobStateQueued:       {JobStateProvisioning, JobStateFailed, JobStateCancelled},
+	JobStateProvisioning: {JobStateRunning, JobStateFailed, JobStateCancelled, JobStateLost},
+	JobStateRunning:      {JobStateQueued, JobStateSucceeded, JobStateFailed, JobStateCancelled, JobStateExpired, JobStateLost},
+}
+
+func (s JobState) CanTransitionTo(next JobState) bool {
+	for _, allowed := range jobStateTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s JobState) IsTerminal() bool {
+	return len(jobStateTransitions[s]) == 0
+}
+
+type JobTransition struct {
+	From JobState  `json:"from" example:"pending"`
+	To   JobState  `json:"to" example:"provisioning"`
+	At   time.Time `json:"at" example:"2023-11-01T12:00:00Z"`
+}
+
+func NewGenericJob(id string, name string) GenericJob {
+	return GenericJob{
+		ID:     id,
+		Name:   name,
+		Status: JobStatePending,
+		Transitions: []JobTransition{
+			{To: JobStatePending, At: time.Now().UTC()},
+		},
+	}
+}
+
+// TransitionTo moves the job into the next state and records when it happened.
+// It returns ErrInvalidTransition if the transition table does not allow it.
+func (j *GenericJob) TransitionTo(next JobState) error {
+	if !j.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, j.Status, next)
+	}
+
+	j.Transitions = append(j.Transitions, JobTransition{From: j.Status, To: next, At: time.Now().UTC()})
+	j.Status = next
+
+	return nil
+}
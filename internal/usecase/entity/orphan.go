@@ -0,0 +1,19 @@
+package entity
+
+type ResourceKind string
+
+const (
+	ResourceContainer ResourceKind = "container"
+	ResourceTwccCCS   ResourceKind = "ccs"
+	ResourceImage     ResourceKind = "image"
+)
+
+// Orphan is a container or CCS labeled with a job that no longer owns it,
+// e.g. because the job was deleted while the service was down, or an image
+// no job uses anymore. Orphans are left for the garbage collector.
+type Orphan struct {
+	Kind   ResourceKind `json:"kind" example:"container"`
+	ID     string       `json:"id" example:"4f66ad9a0b2e"`
+	JobID  string       `json:"jobId" example:"0b9b7a43-6f3d-4c1e-9d67-1f0e3a1c2b4d"`
+	Reason string       `json:"reason" example:"job is succeeded"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// PoolWindow sizes the warm CCS pool during a time of day. From and To are
+// offsets into the day, a window whose To is before From wraps around
+// midnight.
+type PoolWindow struct {
+	From time.Duration
+	To   time.Duration
+	Size int
+}
+
+// Contains reports whether an offset into the day falls into the window.
+func (w PoolWindow) Contains(offset time.Duration) bool {
+	if w.To <= w.From {
+		return offset >= w.From || offset < w.To
+	}
+
+	return offset >= w.From && offset < w.To
+}
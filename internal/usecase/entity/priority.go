@@ -0,0 +1,43 @@
+package entity
+
+import "fmt"
+
+type PriorityClass string
+
+const (
+	PriorityLow    PriorityClass = "low"
+	PriorityNormal PriorityClass = "normal"
+	PriorityHigh   PriorityClass = "high"
+	PriorityUrgent PriorityClass = "urgent"
+)
+
+var priorityRanks = map[PriorityClass]int{
+	PriorityLow:    0,
+	PriorityNormal: 1,
+	PriorityHigh:   2,
+	PriorityUrgent: 3,
+}
+
+// ParsePriorityClass validates a priority class, an empty value means normal.
+func ParsePriorityClass(s string) (PriorityClass, error) {
+	if s == "" {
+		return PriorityNormal, nil
+	}
+
+	p := PriorityClass(s)
+	if _, ok := priorityRanks[p]; !ok {
+		return "", fmt.Errorf("unknown priority class %q", s)
+	}
+
+	return p, nil
+}
+
+// Rank orders priority classes, higher ranks are scheduled first. Unknown
+// classes, such as jobs stored before priorities existed, rank as normal.
+func (p PriorityClass) Rank() int {
+	if r, ok := priorityRanks[p]; ok {
+		return r
+	}
+
+	return priorityRanks[PriorityNormal]
+}
@@ -1,11 +1,38 @@
 package entity
 
+import "time"
+
+// Backend is where a training job runs.
+type Backend string
+
+const (
+	BackendDocker Backend = "docker"
+	BackendTwcc   Backend = "twcc"
+)
+
+// QueuedJob is a training job waiting for a free slot on one of the backends.
+type QueuedJob struct {
+	Job       GenericJob `json:"job"`
+	Spec      JobSpec    `json:"spec"`
+	TwccJobId string     `json:"twccJobId" example:"12345"`
+	// PlacementPolicy overrides the configured placement policy, if set.
+	PlacementPolicy string    `json:"placementPolicy" example:"local-first"`
+	EnqueuedAt      time.Time `json:"enqueuedAt"`
+}
+
+// TwccJob is a training job placed on twcc. Jobs queued without a twcc job
+// get one created from Spec before they are submitted.
 type TwccJob struct {
 	Job       GenericJob `json:"job"`
+	Spec      JobSpec    `json:"spec"`
 	TwccJobId string     `json:"jobId" example:"12345"`
 }
 
 type ContainerJob struct {
-	Job             GenericJob `json:"job"`
-	DockerImageName string     `json:"dockerImageName" example:"ubuntu:latest"`
+	Job         GenericJob `json:"job"`
+	Spec        JobSpec    `json:"spec"`
+	ContainerID string     `json:"containerId" example:"4c01db0b339c"`
+	// PlacementPolicy is the policy the job was queued with, a preempted job
+	// is queued with it again.
+	PlacementPolicy string `json:"placementPolicy" example:"local-first"`
 }
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// Webhook is called with the final transition of a job. Payloads are signed
+// with Secret, they are sent unsigned without one.
+type Webhook struct {
+	JobID  string
+	Kind   JobKind
+	URL    string
+	Secret string
+}
+
+// Validate checks that the webhook calls an absolute http or https URL.
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s is not an http or https url", ErrInvalidWebhook, w.URL)
+	}
+
+	return nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryFailed deliveries used up their attempts.
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is the call of a webhook with a job event. Its ID is sent
+// with every attempt, receivers can use it to drop duplicates. EventID is the
+// ID of the event, which is only unique within a run of the service. Pending
+// deliveries are attempted again at NextAttemptAt.
+type WebhookDelivery struct {
+	ID            string                `json:"id" example:"5f0c6f7e-2b1d-4a43-9c1e-8d3b2a7f6e10"`
+	EventID       uint64                `json:"eventId" example:"1698840000000"`
+	JobID         string                `json:"jobId" example:"0b9b7a43-6f3d-4c1e-9d67-1f0e3a1c2b4d"`
+	URL           string                `json:"url" example:"https://ci.example.com/hooks/jobs"`
+	Event         JobEvent              `json:"event"`
+	Status        WebhookDeliveryStatus `json:"status" example:"delivered"`
+	Attempts      []WebhookAttempt      `json:"attempts"`
+	NextAttemptAt time.Time             `json:"nextAttemptAt" example:"2023-11-01T12:00:05Z"`
+	CreatedAt     time.Time             `json:"createdAt" example:"2023-11-01T12:00:00Z"`
+}
+
+// WebhookAttempt is a call of a webhook, StatusCode is 0 if the call did not
+// get a response.
+type WebhookAttempt struct {
+	At         time.Time `json:"at" example:"2023-11-01T12:00:00Z"`
+	StatusCode int       `json:"statusCode,omitempty" example:"200"`
+	Error      string    `json:"error,omitempty"`
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+type GarbageCollectionRequester interface {
+	// Collect removes exited job containers past their retention, dangling
+	// images pulled for jobs and CCS no job owns. A dry run only reports
+	// what would be removed.
+	Collect(ctx context.Context, dryRun bool) (entity.GCReport, error)
+	// LastReport returns the report of the last collection, false if none
+	// ran yet.
+	LastReport() (entity.GCReport, bool)
+}
@@ -0,0 +1,159 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+// ccsWaiter brings up a CCS: it creates the CCS, waits until it is ready,
+// exposes its ports and waits until the entry point is reachable. The steps
+// run as a saga, so a failed bring-up deletes the CCS again.
+type ccsWaiter struct {
+	twcc         ports.TwccManager
+	readyTimeout time.Duration
+	pollInterval time.Duration
+}
+
+// ccsBringUp is a CCS being brought up, the create step sets TwccCCSId and
+// the wait for entry point step EntryPoint.
+type ccsBringUp struct {
+	TwccCCSId  string
+	EntryPoint string
+}
+
+// createStep creates a CCS with spec, unless c already has one, e.g. from
+// before a restart. Its compensation deletes the CCS.
+func (w ccsWaiter) createStep(spec ports.TwccCCSSpec, c *ccsBringUp) sagaStep {
+	return sagaStep{
+		name: "create ccs",
+		run: func() error {
+			if c.TwccCCSId != "" {
+				return nil
+			}
+
+			var err error
+			c.TwccCCSId, err = w.twcc.CreateTwccCCS(spec)
+			return err
+		},
+		compensate: func() error {
+			// The CCS may have been deleted already, e.g. by a job delete.
+			err := w.twcc.DeleteTwccCCS(c.TwccCCSId)
+			if errors.Is(err, ports.ErrTwccNotFound) {
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+// startSteps wait until the CCS of c is ready, expose targetPorts and wait
+// until the entry point of the first of them is reachable. They fail with an
+// InferenceNotReadyError if the CCS failed or did not make it by deadline.
+func (w ccsWaiter) startSteps(ctx context.Context, c *ccsBringUp, targetPorts []int, deadline time.Time) []sagaStep {
+	return []sagaStep{
+		{
+			name: "wait for ccs",
+			run: func() error {
+				return w.waitForCCS(ctx, c.TwccCCSId, deadline)
+			},
+		},
+		{
+			name: "associate ip",
+			run: func() error {
+				return w.twcc.TwccCCSAssociateIP(c.TwccCCSId, targetPorts)
+			},
+		},
+		{
+			name: "wait for entry point",
+			run: func() error {
+				var err error
+				c.EntryPoint, err = w.waitForEntryPoint(ctx, c.TwccCCSId, targetPorts[0], deadline)
+				return err
+			},
+		},
+	}
+}
+
+// poll calls check every PollInterval until it is done or fails, it returns
+// false if the deadline passed first. Transient twcc failures are retried.
+func (w ccsWaiter) poll(ctx context.Context, deadline time.Time, check func() (bool, error)) (bool, error) {
+	for {
+		done, err := check()
+		if err != nil && !ports.IsTwccRetryable(err) {
+			return false, err
+		}
+		if done {
+			return true, nil
+		}
+
+		if !time.Now().Add(w.pollInterval).Before(deadline) {
+			return false, nil
+		}
+
+		timer := time.NewTimer(w.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// waitForCCS waits until twcc reports the CCS Ready, its pod only exists from
+// then on.
+func (w ccsWaiter) waitForCCS(ctx context.Context, twccCCSId string, deadline time.Time) error {
+	var status string
+	ready, err := w.poll(ctx, deadline, func() (bool, error) {
+		s, err := w.twcc.GetTwccCCSStatus(twccCCSId)
+		if err != nil {
+			return false, err
+		}
+		status = s
+
+		switch strings.ToLower(status) {
+		case "ready":
+			return true, nil
+		case "error", "failed":
+			return false, &usecase.InferenceNotReadyError{TwccCCSId: twccCCSId, Status: status}
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !ready {
+		return &usecase.InferenceNotReadyError{TwccCCSId: twccCCSId, Status: status, TimedOut: true}
+	}
+
+	return nil
+}
+
+// waitForEntryPoint waits until the public IP of the CCS is assigned and
+// returns the address of the given container port.
+func (w ccsWaiter) waitForEntryPoint(ctx context.Context, twccCCSId string, port int, deadline time.Time) (string, error) {
+	var entryPoint string
+	ready, err := w.poll(ctx, deadline, func() (bool, error) {
+		var err error
+		entryPoint, err = w.twcc.GetTwccCCSEntryPoint(twccCCSId, port)
+		if errors.Is(err, ports.ErrTwccCCSNotReady) {
+			return false, nil
+		}
+
+		return err == nil, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if !ready {
+		return "", &usecase.InferenceNotReadyError{TwccCCSId: twccCCSId, Status: "Ready", TimedOut: true}
+	}
+
+	return entryPoint, nil
+}
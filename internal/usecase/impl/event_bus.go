@@ -0,0 +1,119 @@
+package impl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// _subscriberBuffer is how many events a subscriber may lag behind before it
+// is dropped.
+const _subscriberBuffer = 64
+
+type eventSubscriber struct {
+	filter usecase.JobEventFilter
+	ch     chan entity.JobEvent
+}
+
+// EventBus publishes the transitions of jobs to subscribers and keeps the
+// latest ones for subscribers that resume.
+type EventBus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	replaySize  int
+	replay      []entity.JobEvent
+	subscribers map[*eventSubscriber]struct{}
+}
+
+// NewEventBus keeps the latest replaySize events. Event IDs start at the
+// current Unix time in milliseconds, so they keep increasing across
+// restarts.
+func NewEventBus(replaySize int) *EventBus {
+	return &EventBus{
+		nextID:      uint64(time.Now().UnixMilli()),
+		replaySize:  max(replaySize, 0),
+		subscribers: make(map[*eventSubscriber]struct{}),
+	}
+}
+
+// publish sends the latest transition of a job to the subscribers. A nil bus
+// drops the event.
+func (b *EventBus) publish(kind entity.JobKind, job entity.GenericJob) {
+	if b == nil || len(job.Transitions) == 0 {
+		return
+	}
+
+	t := job.Transitions[len(job.Transitions)-1]
+	e := entity.JobEvent{Kind: kind, JobID: job.ID, From: t.From, To: t.To, At: t.At}
+	if t.To == entity.JobStateFailed || t.To == entity.JobStateLost {
+		e.FailureReason = job.FailureReason
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e.ID = b.nextID
+	b.nextID++
+
+	if b.replaySize > 0 {
+		if len(b.replay) == b.replaySize {
+			copy(b.replay, b.replay[1:])
+			b.replay = b.replay[:len(b.replay)-1]
+		}
+		b.replay = append(b.replay, e)
+	}
+
+	for s := range b.subscribers {
+		if !s.filter.Matches(e) {
+			continue
+		}
+
+		select {
+		case s.ch <- e:
+		default:
+			// Publishing must not block the managers.
+			b.drop(s)
+		}
+	}
+}
+
+// drop closes the channel of a subscriber, b.mu must be held.
+func (b *EventBus) drop(s *eventSubscriber) {
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.ch)
+	}
+}
+
+func (b *EventBus) Subscribe(ctx context.Context, filter usecase.JobEventFilter, lastEventID uint64) <-chan entity.JobEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []entity.JobEvent
+	if lastEventID > 0 {
+		for _, e := range b.replay {
+			if e.ID > lastEventID && filter.Matches(e) {
+				replay = append(replay, e)
+			}
+		}
+	}
+
+	s := &eventSubscriber{filter: filter, ch: make(chan entity.JobEvent, len(replay)+_subscriberBuffer)}
+	for _, e := range replay {
+		s.ch <- e
+	}
+	b.subscribers[s] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.drop(s)
+	}()
+
+	return s.ch
+}
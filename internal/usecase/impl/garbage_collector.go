@@ -0,0 +1,274 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// GCConfig configures the garbage collector. It runs every Interval, 0 only
+// runs it on demand. Stopped containers are kept for Retention after they
+// exited, so the logs of their jobs stay readable. DryRun turns every run
+// into a dry run.
+type GCConfig struct {
+	Interval  time.Duration
+	Retention time.Duration
+	DryRun    bool
+}
+
+// GarbageCollector removes the containers, images and CCS left behind by
+// jobs. CCS are billed while they exist, so they are removed regardless of
+// the retention.
+type GarbageCollector struct {
+	training   *TrainingJobManager
+	inference  *InferenceJobManager
+	reconciler *Reconciler
+	config     GCConfig
+
+	// mu keeps collections from overlapping.
+	mu   sync.Mutex
+	last *entity.GCReport
+}
+
+func NewGarbageCollector(t *TrainingJobManager, i *InferenceJobManager, r *Reconciler, c GCConfig) *GarbageCollector {
+	return &GarbageCollector{training: t, inference: i, reconciler: r, config: c}
+}
+
+// Start collects every Interval until ctx is cancelled.
+func (uc *GarbageCollector) Start(ctx context.Context) {
+	if uc.config.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(uc.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, _ = uc.Collect(ctx, false)
+			}
+		}
+	}()
+}
+
+// Collect removes what jobs left behind, or only reports it for a dry run.
+// Resources that could not be removed are listed in the report, the error
+// covers the backends that could not be listed.
+func (uc *GarbageCollector) Collect(ctx context.Context, dryRun bool) (entity.GCReport, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	report := entity.GCReport{
+		DryRun:    dryRun || uc.config.DryRun,
+		StartedAt: time.Now().UTC(),
+		Removed:   []entity.Orphan{},
+		Errors:    []string{},
+	}
+
+	// Containers go first, they may still use the dangling images.
+	errs := []error{
+		uc.collectContainers(ctx, &report),
+		uc.collectImages(ctx, &report),
+		uc.collectTwccCCS(&report),
+	}
+	for _, err := range errs {
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+	err := errors.Join(errs...)
+
+	report.FinishedAt = time.Now().UTC()
+	uc.last = &report
+
+	if err != nil {
+		return report, fmt.Errorf("GarbageCollector - Collect: %w", err)
+	}
+
+	return report, nil
+}
+
+// LastReport returns the report of the last collection.
+func (uc *GarbageCollector) LastReport() (entity.GCReport, bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.last == nil {
+		return entity.GCReport{}, false
+	}
+
+	return *uc.last, true
+}
+
+// collectOrphan removes an orphan unless the report is a dry run, and
+// records it.
+func collectOrphan(report *entity.GCReport, o entity.Orphan, remove func() error) {
+	if !report.DryRun {
+		if err := remove(); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s %s: %s", o.Kind, o.ID, err))
+			return
+		}
+	}
+
+	report.Removed = append(report.Removed, o)
+}
+
+// collectContainers removes the stopped containers no active job runs once
+// their retention passed, and the running ones whose job is gone or
+// finished. A running container of an active job may be about to be
+// attached to the job, it is left alone.
+func (uc *GarbageCollector) collectContainers(ctx context.Context, report *entity.GCReport) error {
+	containers, err := uc.training.docker.ListContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("GarbageCollector - collectContainers - uc.training.docker.ListContainers: %w", err)
+	}
+
+	containerJobs, err := uc.training.repo.GetContainerJobList()
+	if err != nil {
+		return fmt.Errorf("GarbageCollector - collectContainers - uc.training.repo.GetContainerJobList: %w", err)
+	}
+
+	jobs, err := uc.training.GetAllJobs()
+	if err != nil {
+		return fmt.Errorf("GarbageCollector - collectContainers - uc.training.GetAllJobs: %w", err)
+	}
+
+	owned := make(map[string]bool, len(containerJobs))
+	for _, j := range containerJobs {
+		owned[j.ContainerID] = true
+	}
+
+	states := make(map[string]entity.JobState, len(jobs))
+	for _, j := range jobs {
+		states[j.ID] = j.Status
+	}
+
+	now := time.Now()
+	for _, c := range containers {
+		if owned[c.ID] {
+			continue
+		}
+
+		state, ok := states[c.JobID]
+		stopped := containerStopped(c.State)
+
+		var reason string
+		switch {
+		case stopped && now.Sub(c.FinishedAt) < uc.config.Retention:
+			continue
+		case !ok:
+			reason = "job not found"
+		case state.IsTerminal():
+			reason = fmt.Sprintf("job is %s", state)
+		case stopped:
+			reason = "job no longer runs the container"
+		default:
+			continue
+		}
+
+		o := entity.Orphan{Kind: entity.ResourceContainer, ID: c.ID, JobID: c.JobID, Reason: reason}
+		collectOrphan(report, o, func() error {
+			if stopped {
+				return uc.training.docker.RemoveContainer(ctx, c.ID)
+			}
+
+			return uc.training.removeContainer(c.ID)
+		})
+	}
+
+	return nil
+}
+
+// collectImages removes the dangling images that were pulled for jobs.
+func (uc *GarbageCollector) collectImages(ctx context.Context, report *entity.GCReport) error {
+	images, err := uc.training.docker.ListDanglingImages(ctx)
+	if err != nil {
+		return fmt.Errorf("GarbageCollector - collectImages - uc.training.docker.ListDanglingImages: %w", err)
+	}
+
+	for _, id := range images {
+		o := entity.Orphan{Kind: entity.ResourceImage, ID: id, Reason: "image is dangling"}
+		collectOrphan(report, o, func() error {
+			return uc.training.docker.RemoveImage(ctx, id)
+		})
+	}
+
+	return nil
+}
+
+// collectTwccCCS removes the CCS labeled with a job that is gone or
+// finished, and the CCS the reconciler found orphaned on startup, like the
+// pool of a previous run. CCS of active jobs may be about to be attached to
+// the job, and unlabeled ones may be warming up for the pool or not belong
+// to the service at all, they are left alone.
+func (uc *GarbageCollector) collectTwccCCS(report *entity.GCReport) error {
+	jobs, err := uc.inference.repo.GetAllInferenceJob()
+	if err != nil {
+		return fmt.Errorf("GarbageCollector - collectTwccCCS - uc.inference.repo.GetAllInferenceJob: %w", err)
+	}
+
+	projects := map[string]bool{"": true}
+	owned := make(map[string]bool, len(jobs))
+	states := make(map[string]entity.JobState, len(jobs))
+	for _, j := range jobs {
+		projects[j.Spec.Project] = true
+		states[j.Job.ID] = j.Job.Status
+		if !j.Job.Status.IsTerminal() && j.TwccCCSId != "" {
+			owned[j.TwccCCSId] = true
+		}
+	}
+
+	startup := make(map[string]entity.Orphan)
+	for _, o := range uc.reconciler.Orphans() {
+		if o.Kind == entity.ResourceTwccCCS {
+			startup[o.ID] = o
+		}
+	}
+
+	seen := make(map[string]bool)
+	var errs []error
+	for project := range projects {
+		sites, err := uc.inference.twcc.ListTwccCCS(project)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("GarbageCollector - collectTwccCCS - uc.inference.twcc.ListTwccCCS: %w", err))
+			continue
+		}
+
+		for _, site := range sites {
+			if seen[site.ID] || owned[site.ID] || uc.inference.pool.owns(site.ID) {
+				continue
+			}
+			seen[site.ID] = true
+
+			var reason string
+			switch state, ok := states[site.JobID]; {
+			case site.JobID == "":
+				o, ok := startup[site.ID]
+				if !ok {
+					continue
+				}
+				reason = o.Reason
+			case !ok:
+				reason = "job not found"
+			case state.IsTerminal():
+				reason = fmt.Sprintf("job is %s", state)
+			default:
+				continue
+			}
+
+			o := entity.Orphan{Kind: entity.ResourceTwccCCS, ID: site.ID, JobID: site.JobID, Reason: reason}
+			collectOrphan(report, o, func() error {
+				return uc.inference.twcc.DeleteTwccCCS(site.ID)
+			})
+		}
+	}
+
+	return errors.Join(errs...)
+}
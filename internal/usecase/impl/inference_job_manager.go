@@ -2,57 +2,187 @@ package impl
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
+	"golang_backend_template/internal/usecase"
 	"golang_backend_template/internal/usecase/entity"
 	"golang_backend_template/internal/usecase/ports"
+	"golang_backend_template/pkg/logger"
 )
 
+// InferenceConfig configures the CCS of inference jobs. Defaults fill the
+// fields an inference job leaves empty. Workers jobs are provisioned at
+// once, a new CCS is polled every PollInterval until it is reachable, for at
+// most ReadyTimeout. A job gets Attempts tries to provision its CCS. Jobs
+// are leased for DefaultTTL unless they ask for another TTL, capped at
+// MaxTTL, expired jobs are reaped every ReapInterval.
+type InferenceConfig struct {
+	Defaults     entity.InferenceSpec
+	Workers      int
+	ReadyTimeout time.Duration
+	PollInterval time.Duration
+	Attempts     int
+	DefaultTTL   time.Duration
+	MaxTTL       time.Duration
+	ReapInterval time.Duration
+}
+
 type InferenceJobManager struct {
-	repo ports.InferenceJobRepo
-	twcc ports.TwccManager
+	repo   ports.InferenceJobRepo
+	twcc   ports.TwccManager
+	pool   *InferencePool
+	waiter ccsWaiter
+	events *EventBus
+	config InferenceConfig
+	l      logger.Interface
+
+	// mu serializes state changes of jobs, provisioning runs concurrently
+	// with deletes.
+	mu sync.Mutex
+	// pending holds the ids of jobs waiting for a provisioning worker.
+	pending []string
+	wake    chan struct{}
+
+	// requests counts the requests proxied to each job.
+	requestsMu sync.Mutex
+	requests   map[string]uint64
 }
 
-func NewInferenceJobManager(m ports.InferenceJobRepo, t ports.TwccManager) *InferenceJobManager {
+func NewInferenceJobManager(m ports.InferenceJobRepo, t ports.TwccManager, p *InferencePool, e *EventBus, c InferenceConfig, l logger.Interface) *InferenceJobManager {
+	c.Workers = max(c.Workers, 1)
+	c.Attempts = max(c.Attempts, 1)
+
 	return &InferenceJobManager{
-		repo: m,
-		twcc: t,
+		repo:     m,
+		twcc:     t,
+		pool:     p,
+		waiter:   ccsWaiter{twcc: t, readyTimeout: c.ReadyTimeout, pollInterval: c.PollInterval},
+		events:   e,
+		config:   c,
+		l:        l,
+		wake:     make(chan struct{}, 1),
+		requests: make(map[string]uint64),
 	}
 }
 
-func (uc *InferenceJobManager) CreateJob(job entity.GenericJob) (string, error) {
-	twccCCSId, err := uc.twcc.CreateTwccCCS()
-	if err != nil {
-		return "", fmt.Errorf("InferenceJobManager - CreateJob - s.twcc.CreateTwccCCS: %w", err)
+// transition moves an inference job into the next state, persists and
+// publishes it.
+func (uc *InferenceJobManager) transition(j *entity.InferenceJob, next entity.JobState) error {
+	if err := j.Job.TransitionTo(next); err != nil {
+		return err
 	}
 
-	time.Sleep(1 * time.Second / 2)
-	err = uc.twcc.TwccCCSAssociateIP(twccCCSId)
-	if err != nil {
-		return "", fmt.Errorf("InferenceJobManager - CreateJob - s.twcc.TwccCCSAssociateIP: %w", err)
+	if err := uc.repo.StoreInferenceJob(*j); err != nil {
+		return err
+	}
+	uc.events.publish(entity.JobKindInference, j.Job)
+
+	return nil
+}
+
+// twccCCSSpec builds the CCS of an inference job.
+func twccCCSSpec(spec entity.InferenceSpec) ports.TwccCCSSpec {
+	ccs := ports.TwccCCSSpec{
+		Name:    "inference-service",
+		Image:   spec.Image,
+		Flavor:  spec.Flavor,
+		Replica: spec.Replica,
+		Project: spec.Project,
+		Mounts:  make(map[string]string, len(spec.Mounts)),
+	}
+	for _, m := range spec.Mounts {
+		ccs.Mounts[m.Storage] = m.Path
 	}
 
-	entryPoint, err := uc.twcc.GetTwccCCSEntryPoint(twccCCSId)
+	return ccs
+}
+
+// ttl picks the lease of a job, the default for 0 and at most MaxTTL.
+func (uc *InferenceJobManager) ttl(requested time.Duration) time.Duration {
+	if requested <= 0 {
+		requested = uc.config.DefaultTTL
+	}
+	if uc.config.MaxTTL > 0 {
+		requested = min(requested, uc.config.MaxTTL)
+	}
+
+	return requested
+}
+
+// CreateJob stores the job as provisioning and hands it to the provisioning
+// workers, which move it to running once its entry point is reachable.
+func (uc *InferenceJobManager) CreateJob(job entity.GenericJob, spec entity.InferenceSpec, ttl time.Duration) error {
+	spec = spec.WithDefaults(uc.config.Defaults)
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("InferenceJobManager - CreateJob - spec.Validate: %w", err)
+	}
+
+	if spec.Image == "" || spec.Flavor == "" || len(spec.Ports) == 0 {
+		return fmt.Errorf("InferenceJobManager - CreateJob - %w: image, flavor and a port are required",
+			entity.ErrInvalidJobSpec)
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	// The lease starts once the job runs, provisioning may take a while.
+	inferenceJob := entity.InferenceJob{Job: job, Spec: spec, TTL: uc.ttl(ttl)}
+	err := uc.transition(&inferenceJob, entity.JobStateProvisioning)
 	if err != nil {
-		return "", fmt.Errorf("InferenceJobManager - CreateJob - s.twcc.GetTwccCCSEntryPoint: %w", err)
+		return fmt.Errorf("InferenceJobManager - CreateJob - uc.transition: %w", err)
 	}
 
-	job.Status = "inference running on twcc"
-	err = uc.repo.StoreInferenceJob(entity.InferenceJob{Job: job, TwccCCSId: twccCCSId, EntryPoint: entryPoint})
+	uc.enqueue(job.ID)
+
+	return nil
+}
+
+// RenewJob extends the lease of a job that has not finished yet. A job that
+// is still provisioning keeps the new ttl, its lease starts once it runs.
+func (uc *InferenceJobManager) RenewJob(id string, ttl time.Duration) (time.Time, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	job, err := uc.repo.GetInferenceJob(id)
 	if err != nil {
-		return "", fmt.Errorf("InferenceJobManager - CreateJob - s.repo.CreateInferenceJob: %w", err)
+		return time.Time{}, fmt.Errorf("InferenceJobManager - RenewJob - %w: %s", usecase.ErrJobNotFound, id)
+	}
+
+	if job.Job.Status.IsTerminal() {
+		return time.Time{}, fmt.Errorf("InferenceJobManager - RenewJob - %w: %s", usecase.ErrJobFinished, job.Job.Status)
+	}
+
+	if ttl <= 0 {
+		ttl = job.TTL
+	}
+	job.TTL = uc.ttl(ttl)
+	job.ExpiresAt = time.Now().UTC().Add(job.TTL)
+
+	if err := uc.repo.StoreInferenceJob(job); err != nil {
+		return time.Time{}, fmt.Errorf("InferenceJobManager - RenewJob - s.repo.StoreInferenceJob: %w", err)
 	}
 
-	return entryPoint, nil
+	return job.ExpiresAt, nil
 }
 
-func (uc *InferenceJobManager) GetJob(id string) (entity.GenericJob, error) {
+func (uc *InferenceJobManager) GetJob(id string) (entity.InferenceJob, error) {
 	job, err := uc.repo.GetInferenceJob(id)
 	if err != nil {
-		return entity.GenericJob{}, fmt.Errorf("InferenceJobManager - GetJob - s.repo.GetInferenceJob: %w", err)
+		return entity.InferenceJob{}, fmt.Errorf("InferenceJobManager - GetJob - %w: %s", usecase.ErrJobNotFound, id)
 	}
 
-	return job.Job, nil
+	uc.requestsMu.Lock()
+	job.ProxyRequests = uc.requests[id]
+	uc.requestsMu.Unlock()
+
+	return job, nil
+}
+
+func (uc *InferenceJobManager) CountProxyRequest(id string) {
+	uc.requestsMu.Lock()
+	defer uc.requestsMu.Unlock()
+	uc.requests[id]++
 }
 
 func (uc *InferenceJobManager) GetAllJobs() ([]entity.GenericJob, error) {
@@ -69,30 +199,33 @@ func (uc *InferenceJobManager) GetAllJobs() ([]entity.GenericJob, error) {
 	return genericJobs, nil
 }
 
-func (uc *InferenceJobManager) getTwccCCSId(id string) (string, error) {
+// DeleteJob cancels a job and hands its CCS back to the pool, which deletes
+// it unless it is recycled. A job that is still being provisioned has its
+// CCS deleted by the worker once it is created.
+func (uc *InferenceJobManager) DeleteJob(id string) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
 	job, err := uc.repo.GetInferenceJob(id)
 	if err != nil {
-		return "", fmt.Errorf("InferenceJobManager - GetTwccJobId - s.repo.GetInferenceJob: %w", err)
+		return fmt.Errorf("InferenceJobManager - DeleteJob - s.repo.GetInferenceJob: %w", err)
 	}
 
-	return job.TwccCCSId, nil
-}
-
-func (uc *InferenceJobManager) DeleteJob(id string) error {
-	twccCCSId, err := uc.getTwccCCSId(id)
-
-	if err != nil {
-		return fmt.Errorf("InferenceJobManager - DeleteJob - s.getTwccCCSId: %w", err)
+	if !job.Job.Status.CanTransitionTo(entity.JobStateCancelled) {
+		return fmt.Errorf("InferenceJobManager - DeleteJob - %w: %s -> %s",
+			entity.ErrInvalidTransition, job.Job.Status, entity.JobStateCancelled)
 	}
 
-	err = uc.twcc.DeleteTwccCCS(twccCCSId)
-	if err != nil {
-		return fmt.Errorf("InferenceJobManager - DeleteJob - s.twcc.DeleteTwccCCS: %w", err)
+	if job.TwccCCSId != "" {
+		err = uc.pool.Release(job.TwccCCSId, job.EntryPoint, job.Spec)
+		if err != nil {
+			return fmt.Errorf("InferenceJobManager - DeleteJob - uc.pool.Release: %w", err)
+		}
 	}
 
-	err = uc.repo.DeleteInferenceJob(id)
+	err = uc.transition(&job, entity.JobStateCancelled)
 	if err != nil {
-		return fmt.Errorf("InferenceJobManager - DeleteJob - s.repo.DeleteInferenceJob: %w", err)
+		return fmt.Errorf("InferenceJobManager - DeleteJob - uc.transition: %w", err)
 	}
 
 	return nil
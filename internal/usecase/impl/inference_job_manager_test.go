@@ -0,0 +1,157 @@
+package impl
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/adapter"
+	"golang_backend_template/internal/infra/adapter/twcctest"
+	"golang_backend_template/internal/infra/memo"
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/pkg/logger"
+)
+
+// _inferenceDefaults is the default spec of the inference job managers of the
+// tests.
+var _inferenceDefaults = entity.InferenceSpec{
+	Image:   "triton-24.08-py3:latest",
+	Flavor:  "1 GPU + 04 cores + 090GB memory",
+	Replica: 1,
+	Ports:   []int{8000},
+}
+
+// buildInferenceJobManager creates an inference job manager and its pool on
+// a fake twcc gateway without starting them.
+func buildInferenceJobManager(s *twcctest.Server, defaults entity.InferenceSpec, pc InferencePoolConfig) *InferenceJobManager {
+	twcc := adapter.NewTwccAdapter(s.Config())
+	config := InferenceConfig{
+		Defaults:     defaults,
+		Workers:      1,
+		ReadyTimeout: time.Second,
+		PollInterval: 5 * time.Millisecond,
+		Attempts:     2,
+		DefaultTTL:   time.Hour,
+		MaxTTL:       time.Hour,
+		ReapInterval: time.Minute,
+	}
+	l := logger.New("error")
+	pool := NewInferencePool(twcc, config.Defaults, pc, config, l)
+
+	return NewInferenceJobManager(memo.NewInferenceJobsMemory(), twcc, pool, NewEventBus(16), config, l)
+}
+
+func newInferenceJobManager(t *testing.T, s *twcctest.Server, pc InferencePoolConfig) *InferenceJobManager {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	m := buildInferenceJobManager(s, _inferenceDefaults, pc)
+	m.pool.Start(ctx)
+	m.Start(ctx)
+
+	return m
+}
+
+func TestInferenceJobManagerProvisionsCCS(t *testing.T) {
+	s := twcctest.NewServer()
+	defer s.Close()
+	m := newInferenceJobManager(t, s, InferencePoolConfig{})
+
+	if err := m.CreateJob(entity.NewGenericJob("job-1", "infer"), entity.InferenceSpec{}, 0); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	waitFor(t, "the job to run", func() bool {
+		j, err := m.GetJob("job-1")
+		return err == nil && j.Job.Status == entity.JobStateRunning
+	})
+
+	j, _ := m.GetJob("job-1")
+	site, ok := s.Site(j.TwccCCSId)
+	if !ok {
+		t.Fatalf("ccs %s of the job was not created", j.TwccCCSId)
+	}
+	if site.Image != "triton-24.08-py3:latest" || !strings.HasSuffix(site.Desc, "=job-1") {
+		t.Errorf("ccs image %q desc %q", site.Image, site.Desc)
+	}
+	if !strings.HasPrefix(j.EntryPoint, site.PublicIP+":") {
+		t.Errorf("entry point %q, want an address on %s", j.EntryPoint, site.PublicIP)
+	}
+	if j.ExpiresAt.IsZero() {
+		t.Error("the lease did not start once the job ran")
+	}
+
+	m.CountProxyRequest("job-1")
+	m.CountProxyRequest("job-1")
+	if j, _ := m.GetJob("job-1"); j.ProxyRequests != 2 {
+		t.Errorf("%d proxied requests, want 2", j.ProxyRequests)
+	}
+
+	if err := m.DeleteJob("job-1"); err != nil {
+		t.Fatalf("DeleteJob: %v", err)
+	}
+	if site, _ := s.Site(j.TwccCCSId); !site.Deleted {
+		t.Error("the ccs of the deleted job was not deleted")
+	}
+}
+
+func TestInferenceJobManagerRollsBackFailedCCS(t *testing.T) {
+	s := twcctest.NewServer()
+	defer s.Close()
+	s.DefaultSiteScript = []string{twcctest.SiteStatusPending, twcctest.SiteStatusError}
+	m := newInferenceJobManager(t, s, InferencePoolConfig{})
+
+	if err := m.CreateJob(entity.NewGenericJob("job-1", "infer"), entity.InferenceSpec{}, 0); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	waitFor(t, "the job to fail", func() bool {
+		j, err := m.GetJob("job-1")
+		return err == nil && j.Job.Status == entity.JobStateFailed
+	})
+
+	j, _ := m.GetJob("job-1")
+	if len(j.Attempts) != 2 {
+		t.Fatalf("%d attempts, want 2", len(j.Attempts))
+	}
+	for _, attempt := range j.Attempts {
+		if attempt.TwccCCSId == "" || attempt.RollbackError != "" {
+			t.Errorf("attempt with ccs %q rolled back with %q", attempt.TwccCCSId, attempt.RollbackError)
+		}
+	}
+	if sites := s.Sites(); len(sites) != 0 {
+		t.Errorf("%d ccs left behind by the failed attempts", len(sites))
+	}
+}
+
+func TestInferenceJobManagerUsesWarmCCS(t *testing.T) {
+	s := twcctest.NewServer()
+	defer s.Close()
+	m := newInferenceJobManager(t, s, InferencePoolConfig{Size: 1, Interval: time.Hour})
+
+	var warm twcctest.Site
+	waitFor(t, "the pool to warm up a ccs", func() bool {
+		for _, site := range s.Sites() {
+			if m.pool.owns(site.ID) {
+				warm = site
+				return true
+			}
+		}
+		return false
+	})
+
+	if err := m.CreateJob(entity.NewGenericJob("job-1", "infer"), entity.InferenceSpec{}, 0); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	waitFor(t, "the job to run", func() bool {
+		j, err := m.GetJob("job-1")
+		return err == nil && j.Job.Status == entity.JobStateRunning
+	})
+
+	if j, _ := m.GetJob("job-1"); j.TwccCCSId != warm.ID || !strings.HasPrefix(j.EntryPoint, warm.PublicIP+":") {
+		t.Errorf("job got ccs %s on %q, want the warm ccs %s", j.TwccCCSId, j.EntryPoint, warm.ID)
+	}
+}
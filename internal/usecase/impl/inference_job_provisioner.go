@@ -0,0 +1,249 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// errNotProvisioning is returned when a job left the provisioning state while
+// its CCS was being set up, e.g. because it was deleted.
+var errNotProvisioning = errors.New("job is no longer provisioning")
+
+// Start runs the provisioning workers and the expiry reaper until ctx is
+// cancelled. Jobs that were still provisioning before a restart are picked
+// up again.
+func (uc *InferenceJobManager) Start(ctx context.Context) {
+	if jobs, err := uc.repo.GetAllInferenceJob(); err == nil {
+		for _, j := range jobs {
+			if j.Job.Status == entity.JobStateProvisioning {
+				uc.mu.Lock()
+				uc.enqueue(j.Job.ID)
+				uc.mu.Unlock()
+			}
+		}
+	}
+
+	uc.startReaper(ctx)
+
+	for i := 0; i < uc.config.Workers; i++ {
+		go func() {
+			for {
+				id, ok := uc.next()
+				if !ok {
+					select {
+					case <-ctx.Done():
+						return
+					case <-uc.wake:
+					}
+
+					continue
+				}
+
+				uc.provision(ctx, id)
+			}
+		}()
+	}
+}
+
+// enqueue hands a job to the workers, uc.mu must be held.
+func (uc *InferenceJobManager) enqueue(id string) {
+	uc.pending = append(uc.pending, id)
+
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next pops the oldest pending job and wakes another worker if more are
+// left.
+func (uc *InferenceJobManager) next() (string, bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if len(uc.pending) == 0 {
+		return "", false
+	}
+
+	id := uc.pending[0]
+	uc.pending = uc.pending[1:]
+	if len(uc.pending) > 0 {
+		select {
+		case uc.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return id, true
+}
+
+// updateProvisioning applies update to a job that is still provisioning and
+// persists it. Moving the job out of provisioning is published.
+func (uc *InferenceJobManager) updateProvisioning(id string, update func(*entity.InferenceJob) error) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	j, err := uc.repo.GetInferenceJob(id)
+	if err != nil {
+		return err
+	}
+
+	if j.Job.Status != entity.JobStateProvisioning {
+		return errNotProvisioning
+	}
+
+	if err := update(&j); err != nil {
+		return err
+	}
+
+	if err := uc.repo.StoreInferenceJob(j); err != nil {
+		return err
+	}
+	if j.Job.Status != entity.JobStateProvisioning {
+		uc.events.publish(entity.JobKindInference, j.Job)
+	}
+
+	return nil
+}
+
+// startJob moves a provisioned job to running and starts its lease.
+func startJob(j *entity.InferenceJob, entryPoint string) error {
+	j.EntryPoint = entryPoint
+	j.ExpiresAt = time.Now().UTC().Add(j.TTL)
+
+	return j.Job.TransitionTo(entity.JobStateRunning)
+}
+
+// provision takes a warm CCS from the pool, or provisions a new CCS for the
+// job. A failed attempt is rolled back and retried with a new CCS until the
+// job used up its attempts, then the job fails. Provisioning stopped by
+// shutting down is left to be resumed on the next start.
+func (uc *InferenceJobManager) provision(ctx context.Context, id string) {
+	j, err := uc.repo.GetInferenceJob(id)
+	if err != nil || j.Job.Status != entity.JobStateProvisioning {
+		return
+	}
+
+	if j.TwccCCSId == "" {
+		if twccCCSId, entryPoint, ok := uc.pool.Acquire(j.Spec); ok {
+			err = uc.updateProvisioning(id, func(j *entity.InferenceJob) error {
+				j.TwccCCSId = twccCCSId
+				return startJob(j, entryPoint)
+			})
+			if err != nil {
+				// The job is gone or could not be stored, the CCS goes back.
+				if err := uc.pool.Release(twccCCSId, entryPoint, j.Spec); err != nil {
+					uc.l.Error(err, "InferenceJobManager - provision - uc.pool.Release")
+				}
+			}
+
+			return
+		}
+	}
+
+	for {
+		attempt, err := uc.provisionCCS(ctx, j)
+		if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, errNotProvisioning) {
+			return
+		}
+
+		retry, err := uc.recordAttempt(id, attempt)
+		if err != nil || !retry {
+			return
+		}
+
+		if j, err = uc.repo.GetInferenceJob(id); err != nil {
+			return
+		}
+	}
+}
+
+// provisionCCS creates the CCS of a job, or picks up the one it got before a
+// restart, waits until its entry point is reachable and moves the job to
+// running. A failed attempt deletes the CCS again and detaches it from the
+// job, unless it was stopped by shutting down.
+func (uc *InferenceJobManager) provisionCCS(ctx context.Context, j entity.InferenceJob) (entity.ProvisioningAttempt, error) {
+	id := j.Job.ID
+	attempt := entity.ProvisioningAttempt{StartedAt: time.Now().UTC()}
+	deadline := time.Now().Add(uc.config.ReadyTimeout)
+
+	spec := twccCCSSpec(j.Spec)
+	spec.JobID = id
+	ccs := &ccsBringUp{TwccCCSId: j.TwccCCSId}
+
+	steps := []sagaStep{
+		uc.waiter.createStep(spec, ccs),
+		{
+			name: "attach ccs",
+			run: func() error {
+				return uc.updateProvisioning(id, func(j *entity.InferenceJob) error {
+					j.TwccCCSId = ccs.TwccCCSId
+					return nil
+				})
+			},
+			compensate: func() error {
+				err := uc.updateProvisioning(id, func(j *entity.InferenceJob) error {
+					j.TwccCCSId = ""
+					return nil
+				})
+				if errors.Is(err, errNotProvisioning) {
+					return nil
+				}
+				return err
+			},
+		},
+	}
+	steps = append(steps, uc.waiter.startSteps(ctx, ccs, j.Spec.Ports, deadline)...)
+	steps = append(steps, sagaStep{
+		name: "start job",
+		run: func() error {
+			return uc.updateProvisioning(id, func(j *entity.InferenceJob) error {
+				return startJob(j, ccs.EntryPoint)
+			})
+		},
+	})
+	s := &saga{steps: steps}
+
+	err := s.run()
+	attempt.TwccCCSId = ccs.TwccCCSId
+	if err == nil {
+		return attempt, nil
+	}
+	if errors.Is(err, context.Canceled) {
+		return attempt, err
+	}
+
+	var failed *sagaError
+	if errors.As(err, &failed) {
+		attempt.Step = failed.Step
+		attempt.Reason = failed.Err.Error()
+	}
+	attempt.FailedAt = time.Now().UTC()
+
+	if rollbackErr := s.rollback(); rollbackErr != nil {
+		attempt.RollbackError = rollbackErr.Error()
+	}
+
+	return attempt, fmt.Errorf("InferenceJobManager - provisionCCS - s.run: %w", err)
+}
+
+// recordAttempt records a failed attempt on a job that is still
+// provisioning. The job fails with the reason of its last attempt once it
+// used up its attempts, otherwise recordAttempt reports that it is retried.
+func (uc *InferenceJobManager) recordAttempt(id string, attempt entity.ProvisioningAttempt) (bool, error) {
+	retry := false
+	err := uc.updateProvisioning(id, func(j *entity.InferenceJob) error {
+		j.Attempts = append(j.Attempts, attempt)
+		if len(j.Attempts) < uc.config.Attempts {
+			retry = true
+			return nil
+		}
+
+		j.Job.FailureReason = fmt.Sprintf("%s: %s", attempt.Step, attempt.Reason)
+		return j.Job.TransitionTo(entity.JobStateFailed)
+	})
+
+	return retry && err == nil, err
+}
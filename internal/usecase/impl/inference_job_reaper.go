@@ -0,0 +1,69 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// startReaper tears down running jobs whose lease expired, right away and
+// then every ReapInterval until ctx is cancelled. The expiry is stored with
+// the job, so leases survive restarts.
+func (uc *InferenceJobManager) startReaper(ctx context.Context) {
+	go func() {
+		uc.reap()
+
+		if uc.config.ReapInterval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(uc.config.ReapInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				uc.reap()
+			}
+		}
+	}()
+}
+
+// reap expires all running jobs past their lease. A job whose CCS could not
+// be deleted is retried on the next run.
+func (uc *InferenceJobManager) reap() {
+	jobs, err := uc.repo.GetAllInferenceJob()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, j := range jobs {
+		if j.Job.Status == entity.JobStateRunning && j.Expired(now) {
+			uc.expire(j.Job.ID, now)
+		}
+	}
+}
+
+func (uc *InferenceJobManager) expire(id string, now time.Time) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	// The job may have been renewed or deleted since it was listed.
+	j, err := uc.repo.GetInferenceJob(id)
+	if err != nil || j.Job.Status != entity.JobStateRunning || !j.Expired(now) {
+		return err
+	}
+
+	if j.TwccCCSId != "" {
+		if err := uc.pool.Release(j.TwccCCSId, j.EntryPoint, j.Spec); err != nil {
+			return fmt.Errorf("InferenceJobManager - expire - uc.pool.Release: %w", err)
+		}
+	}
+
+	return uc.transition(&j, entity.JobStateExpired)
+}
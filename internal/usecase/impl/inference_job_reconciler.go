@@ -0,0 +1,144 @@
+package impl
+
+import (
+	"fmt"
+
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+// reconcile matches the active jobs with the CCS left after a restart, in
+// the default project and the projects of the jobs. A job whose CCS is gone
+// is lost, a CCS created right before the restart is attached to its job.
+// CCS labeled with a job that is unknown, finished or runs another CCS are
+// orphans. Idle CCS of the pool go back into the pool, unless its spec
+// changed or it is full, then they are orphans too.
+// It runs before Start, which resumes provisioning.
+func (uc *InferenceJobManager) reconcile(report *ReconcileReport) error {
+	jobs, err := uc.repo.GetAllInferenceJob()
+	if err != nil {
+		return fmt.Errorf("InferenceJobManager - reconcile - uc.repo.GetAllInferenceJob: %w", err)
+	}
+
+	projects := map[string]bool{"": true, uc.pool.spec.Project: true}
+	for _, j := range jobs {
+		if !j.Job.Status.IsTerminal() {
+			projects[j.Spec.Project] = true
+		}
+	}
+
+	// The default project may also be named by a job, so CCS are keyed by id.
+	sites := make(map[string]ports.TwccCCS)
+	for project := range projects {
+		list, err := uc.twcc.ListTwccCCS(project)
+		if err != nil {
+			return fmt.Errorf("InferenceJobManager - reconcile - uc.twcc.ListTwccCCS: %w", err)
+		}
+
+		for _, site := range list {
+			sites[site.ID] = site
+		}
+	}
+
+	byJob := make(map[string]string)
+	for _, site := range sites {
+		if site.JobID != "" {
+			byJob[site.JobID] = site.ID
+		}
+	}
+
+	owned := make(map[string]bool)
+	for _, j := range jobs {
+		if j.Job.Status.IsTerminal() {
+			continue
+		}
+
+		if j.TwccCCSId == "" {
+			twccCCSId, ok := byJob[j.Job.ID]
+			if !ok {
+				continue
+			}
+
+			err := uc.updateProvisioning(j.Job.ID, func(j *entity.InferenceJob) error {
+				j.TwccCCSId = twccCCSId
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("InferenceJobManager - reconcile - uc.updateProvisioning: %w", err)
+			}
+
+			j.TwccCCSId = twccCCSId
+		}
+
+		if _, ok := sites[j.TwccCCSId]; !ok {
+			if err := uc.lose(j.Job.ID, fmt.Errorf("ccs %s vanished", j.TwccCCSId)); err != nil {
+				return fmt.Errorf("InferenceJobManager - reconcile - uc.lose: %w", err)
+			}
+
+			report.Lost = append(report.Lost, j.Job.ID)
+
+			continue
+		}
+
+		owned[j.TwccCCSId] = true
+		report.Adopted = append(report.Adopted, j.Job.ID)
+	}
+
+	states := make(map[string]entity.JobState, len(jobs))
+	for _, j := range jobs {
+		states[j.Job.ID] = j.Job.Status
+	}
+
+	for _, site := range sites {
+		if owned[site.ID] {
+			continue
+		}
+
+		var reason string
+		switch state, ok := states[site.JobID]; {
+		case site.JobID == "" && site.Name == _poolCCSName:
+			err := uc.pool.adopt(site)
+			if err == nil {
+				continue
+			}
+			reason = err.Error()
+		case site.JobID == "":
+			// Not created by the service.
+			continue
+		case !ok:
+			reason = "job not found"
+		case state.IsTerminal():
+			reason = fmt.Sprintf("job is %s", state)
+		default:
+			reason = "job no longer runs the ccs"
+		}
+
+		report.Orphans = append(report.Orphans, entity.Orphan{
+			Kind:   entity.ResourceTwccCCS,
+			ID:     site.ID,
+			JobID:  site.JobID,
+			Reason: reason,
+		})
+	}
+
+	return nil
+}
+
+// lose marks an active job as lost.
+func (uc *InferenceJobManager) lose(id string, err error) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	j, getErr := uc.repo.GetInferenceJob(id)
+	if getErr != nil {
+		return getErr
+	}
+
+	if j.Job.Status.IsTerminal() {
+		return nil
+	}
+
+	j.Job.FailureReason = err.Error()
+
+	return uc.transition(&j, entity.JobStateLost)
+}
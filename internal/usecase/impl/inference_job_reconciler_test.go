@@ -0,0 +1,92 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/adapter/twcctest"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// warmPool runs a pool of the given size until it warmed up, and returns the
+// ids of its CCS.
+func warmPool(t *testing.T, s *twcctest.Server, size int) []string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := buildInferenceJobManager(s, _inferenceDefaults, InferencePoolConfig{Size: size, Interval: time.Hour})
+	m.pool.Start(ctx)
+
+	var ids []string
+	waitFor(t, "the pool to warm up", func() bool {
+		ids = ids[:0]
+		for _, site := range s.Sites() {
+			if m.pool.owns(site.ID) {
+				ids = append(ids, site.ID)
+			}
+		}
+		return len(ids) == size
+	})
+
+	return ids
+}
+
+func TestReconcileAdoptsPoolCCS(t *testing.T) {
+	s := twcctest.NewServer()
+	defer s.Close()
+	warm := warmPool(t, s, 2)
+
+	// The service restarts with the same pool.
+	m := buildInferenceJobManager(s, _inferenceDefaults, InferencePoolConfig{Size: 2, Interval: time.Hour})
+	var report ReconcileReport
+	if err := m.reconcile(&report); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(report.Orphans) != 0 {
+		t.Errorf("orphans %+v, want the pool ccs adopted", report.Orphans)
+	}
+	for _, id := range warm {
+		if !m.pool.owns(id) {
+			t.Errorf("pool ccs %s was not adopted", id)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.pool.Start(ctx)
+	m.Start(ctx)
+
+	if err := m.CreateJob(entity.NewGenericJob("job-1", "infer"), entity.InferenceSpec{}, 0); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	waitFor(t, "the job to run", func() bool {
+		j, err := m.GetJob("job-1")
+		return err == nil && j.Job.Status == entity.JobStateRunning
+	})
+	if j, _ := m.GetJob("job-1"); j.TwccCCSId != warm[0] && j.TwccCCSId != warm[1] {
+		t.Errorf("job got ccs %s, want an adopted one of %v", j.TwccCCSId, warm)
+	}
+}
+
+func TestReconcileOrphansPoolCCSOfAnotherSpec(t *testing.T) {
+	s := twcctest.NewServer()
+	defer s.Close()
+	warm := warmPool(t, s, 1)
+
+	// The service restarts with another default image.
+	defaults := _inferenceDefaults
+	defaults.Image = "triton-24.09-py3:latest"
+	m := buildInferenceJobManager(s, defaults, InferencePoolConfig{Size: 1, Interval: time.Hour})
+	var report ReconcileReport
+	if err := m.reconcile(&report); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(report.Orphans) != 1 || report.Orphans[0].ID != warm[0] {
+		t.Errorf("orphans %+v, want the pool ccs %s", report.Orphans, warm[0])
+	}
+	if m.pool.owns(warm[0]) {
+		t.Errorf("pool ccs %s of another spec was adopted", warm[0])
+	}
+}
@@ -0,0 +1,281 @@
+package impl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+	"golang_backend_template/pkg/logger"
+)
+
+// _poolCCSName names the CCS of the pool, which belong to no job.
+const _poolCCSName = "inference-pool"
+
+// InferencePoolConfig sizes the warm pool. The pool keeps Size idle CCS, or
+// the size of the Schedule window the local time of day falls into, and is
+// topped up every Interval. With Recycle the CCS of a deleted job goes back
+// into the pool if there is room, otherwise it is deleted.
+type InferencePoolConfig struct {
+	Size     int
+	Schedule []entity.PoolWindow
+	Interval time.Duration
+	Recycle  bool
+}
+
+// warmCCS is an idle CCS whose entry point is already reachable.
+type warmCCS struct {
+	twccCCSId  string
+	entryPoint string
+}
+
+// InferencePool keeps CCS with the default spec warm, so inference jobs that
+// use the default spec start without waiting for a new CCS.
+type InferencePool struct {
+	twcc   ports.TwccManager
+	waiter ccsWaiter
+	spec   entity.InferenceSpec
+	// fingerprint labels the CCS of the pool with its spec.
+	fingerprint string
+	config      InferencePoolConfig
+	l           logger.Interface
+
+	mu   sync.Mutex
+	idle []warmCCS
+	// warming counts the CCS being brought up.
+	warming int
+	wake    chan struct{}
+}
+
+func NewInferencePool(t ports.TwccManager, spec entity.InferenceSpec, c InferencePoolConfig, ic InferenceConfig, l logger.Interface) *InferencePool {
+	return &InferencePool{
+		twcc:        t,
+		waiter:      ccsWaiter{twcc: t, readyTimeout: ic.ReadyTimeout, pollInterval: ic.PollInterval},
+		spec:        spec,
+		fingerprint: specFingerprint(spec),
+		config:      c,
+		l:           l,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// specFingerprint returns a short hash of a spec, CCS descriptions have no
+// room for the spec itself.
+func specFingerprint(spec entity.InferenceSpec) string {
+	b, _ := json.Marshal(spec)
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// target returns the size the pool should have at the given time.
+func (p *InferencePool) target(now time.Time) int {
+	year, month, day := now.Date()
+	offset := now.Sub(time.Date(year, month, day, 0, 0, 0, 0, now.Location()))
+
+	for _, w := range p.config.Schedule {
+		if w.Contains(offset) {
+			return w.Size
+		}
+	}
+
+	return p.config.Size
+}
+
+func (p *InferencePool) matches(spec entity.InferenceSpec) bool {
+	return reflect.DeepEqual(spec, p.spec)
+}
+
+// Acquire hands out an idle CCS for a job with the given spec.
+func (p *InferencePool) Acquire(spec entity.InferenceSpec) (twccCCSId string, entryPoint string, ok bool) {
+	if !p.matches(spec) {
+		return "", "", false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle) == 0 {
+		return "", "", false
+	}
+
+	ccs := p.idle[0]
+	p.idle = p.idle[1:]
+	p.wakeBackfill()
+
+	return ccs.twccCCSId, ccs.entryPoint, true
+}
+
+// owns reports whether a CCS is idle in the pool.
+func (p *InferencePool) owns(twccCCSId string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ccs := range p.idle {
+		if ccs.twccCCSId == twccCCSId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adopt takes back an idle CCS the pool created before a restart. It fails
+// for CCS of another spec, that are not ready, or if the pool is full.
+// It runs before Start.
+func (p *InferencePool) adopt(site ports.TwccCCS) error {
+	if site.Name != _poolCCSName || site.PoolSpec != p.fingerprint {
+		return errors.New("pool ccs with another spec")
+	}
+
+	if !strings.EqualFold(site.Status, "ready") || len(p.spec.Ports) == 0 {
+		return fmt.Errorf("pool ccs is %s", site.Status)
+	}
+
+	entryPoint, err := p.twcc.GetTwccCCSEntryPoint(site.ID, p.spec.Ports[0])
+	if err != nil {
+		return fmt.Errorf("InferencePool - adopt - p.twcc.GetTwccCCSEntryPoint: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ccs := range p.idle {
+		if ccs.twccCCSId == site.ID {
+			return nil
+		}
+	}
+	if len(p.idle)+p.warming >= p.target(time.Now()) {
+		return errors.New("pool is full")
+	}
+
+	p.idle = append(p.idle, warmCCS{twccCCSId: site.ID, entryPoint: entryPoint})
+
+	return nil
+}
+
+// Release takes back the CCS of a job that ended. It is kept warm if
+// recycling is enabled, it runs the pool spec and the pool has room,
+// otherwise it is deleted.
+func (p *InferencePool) Release(twccCCSId string, entryPoint string, spec entity.InferenceSpec) error {
+	if p.config.Recycle && entryPoint != "" && p.matches(spec) {
+		p.mu.Lock()
+		if len(p.idle)+p.warming < p.target(time.Now()) {
+			p.idle = append(p.idle, warmCCS{twccCCSId: twccCCSId, entryPoint: entryPoint})
+			p.mu.Unlock()
+
+			return nil
+		}
+		p.mu.Unlock()
+	}
+
+	if err := p.twcc.DeleteTwccCCS(twccCCSId); err != nil {
+		return fmt.Errorf("InferencePool - Release - s.twcc.DeleteTwccCCS: %w", err)
+	}
+
+	return nil
+}
+
+// wakeBackfill asks the backfill loop to top up the pool, p.mu must be held.
+func (p *InferencePool) wakeBackfill() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start tops up the pool right away, then every Interval and whenever a CCS
+// was handed out, until ctx is cancelled.
+func (p *InferencePool) Start(ctx context.Context) {
+	go func() {
+		interval := p.config.Interval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			p.backfill(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-p.wake:
+			}
+		}
+	}()
+}
+
+// backfill brings up the missing CCS in the background and deletes surplus
+// idle ones, e.g. once a schedule window with a smaller size started.
+func (p *InferencePool) backfill(ctx context.Context) {
+	p.mu.Lock()
+	target := p.target(time.Now())
+	missing := target - len(p.idle) - p.warming
+	var surplus []warmCCS
+	if extra := len(p.idle) - target; extra > 0 {
+		surplus = p.idle[:extra]
+		p.idle = p.idle[extra:]
+	}
+	p.warming += max(missing, 0)
+	p.mu.Unlock()
+
+	for _, ccs := range surplus {
+		if err := p.twcc.DeleteTwccCCS(ccs.twccCCSId); err != nil {
+			p.l.Error(err, "InferencePool - backfill - p.twcc.DeleteTwccCCS")
+		}
+	}
+
+	for i := 0; i < missing; i++ {
+		go p.warm(ctx)
+	}
+}
+
+// warm brings up one CCS and adds it to the idle ones. A CCS that fails to
+// come up is deleted, the next backfill tries again.
+func (p *InferencePool) warm(ctx context.Context) {
+	ccs, err := p.bringUp(ctx)
+	if err != nil && ctx.Err() == nil {
+		p.l.Warn("InferencePool - warm - p.bringUp: %s", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.warming--
+	if err == nil {
+		p.idle = append(p.idle, ccs)
+	}
+}
+
+// bringUp creates a CCS with the pool spec and waits until its entry point
+// is reachable, the same way a job gets its CCS.
+func (p *InferencePool) bringUp(ctx context.Context) (warmCCS, error) {
+	deadline := time.Now().Add(p.waiter.readyTimeout)
+
+	spec := twccCCSSpec(p.spec)
+	spec.Name = _poolCCSName
+	spec.PoolSpec = p.fingerprint
+	ccs := &ccsBringUp{}
+
+	steps := []sagaStep{p.waiter.createStep(spec, ccs)}
+	s := &saga{steps: append(steps, p.waiter.startSteps(ctx, ccs, p.spec.Ports, deadline)...)}
+	if err := s.run(); err != nil {
+		// A CCS that could not be deleted is billed until the garbage
+		// collector finds it.
+		if rollbackErr := s.rollback(); rollbackErr != nil {
+			p.l.Error(rollbackErr, "InferencePool - bringUp - s.rollback")
+		}
+
+		return warmCCS{}, fmt.Errorf("InferencePool - bringUp - s.run: %w", err)
+	}
+
+	return warmCCS{twccCCSId: ccs.TwccCCSId, entryPoint: ccs.EntryPoint}, nil
+}
@@ -0,0 +1,122 @@
+package impl
+
+import (
+	"fmt"
+	"sync"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// NewPlacementPolicies builds the built-in placement policies by name.
+// localLimit caps the docker jobs of the local-first policy.
+func NewPlacementPolicies(localLimit int) map[string]usecase.PlacementPolicy {
+	return map[string]usecase.PlacementPolicy{
+		usecase.PlacementLocalFirst:  &LocalFirstPolicy{Limit: localLimit},
+		usecase.PlacementRemoteOnly:  &SingleBackendPolicy{Backend: entity.BackendTwcc},
+		usecase.PlacementLocalOnly:   &SingleBackendPolicy{Backend: entity.BackendDocker},
+		usecase.PlacementRoundRobin:  &RoundRobinPolicy{},
+		usecase.PlacementLeastLoaded: &LeastLoadedPolicy{},
+	}
+}
+
+func findLoad(loads []usecase.BackendLoad, b entity.Backend) (usecase.BackendLoad, bool) {
+	for _, l := range loads {
+		if l.Backend == b {
+			return l, true
+		}
+	}
+
+	return usecase.BackendLoad{}, false
+}
+
+// LocalFirstPolicy runs up to Limit jobs on docker and overflows to twcc.
+type LocalFirstPolicy struct {
+	Limit int
+}
+
+func (p *LocalFirstPolicy) Place(_ entity.QueuedJob, loads []usecase.BackendLoad) (entity.Backend, bool) {
+	if l, ok := findLoad(loads, entity.BackendDocker); ok && l.HasCapacity() && l.Running < p.Limit {
+		return entity.BackendDocker, true
+	}
+
+	if l, ok := findLoad(loads, entity.BackendTwcc); ok && l.HasCapacity() {
+		return entity.BackendTwcc, true
+	}
+
+	return "", false
+}
+
+// SingleBackendPolicy only ever places jobs on Backend, which backs the
+// local-only and remote-only policies.
+type SingleBackendPolicy struct {
+	Backend entity.Backend
+}
+
+func (p *SingleBackendPolicy) Place(_ entity.QueuedJob, loads []usecase.BackendLoad) (entity.Backend, bool) {
+	if l, ok := findLoad(loads, p.Backend); ok && l.HasCapacity() {
+		return p.Backend, true
+	}
+
+	return "", false
+}
+
+// RoundRobinPolicy alternates between the backends with free capacity.
+type RoundRobinPolicy struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (p *RoundRobinPolicy) Place(_ entity.QueuedJob, loads []usecase.BackendLoad) (entity.Backend, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := 0; i < len(loads); i++ {
+		l := loads[(p.next+i)%len(loads)]
+		if l.HasCapacity() {
+			p.next = (p.next + i + 1) % len(loads)
+			return l.Backend, true
+		}
+	}
+
+	return "", false
+}
+
+// LeastLoadedPolicy picks the backend with the lowest share of its capacity in use.
+type LeastLoadedPolicy struct{}
+
+func (p *LeastLoadedPolicy) Place(_ entity.QueuedJob, loads []usecase.BackendLoad) (entity.Backend, bool) {
+	var (
+		best     entity.Backend
+		bestLoad float64
+		found    bool
+	)
+
+	for _, l := range loads {
+		if !l.HasCapacity() {
+			continue
+		}
+
+		load := float64(l.Running) / float64(l.Capacity)
+		if !found || load < bestLoad {
+			best, bestLoad, found = l.Backend, load, true
+		}
+	}
+
+	return best, found
+}
+
+// placementPolicy resolves the policy of a queued job, falling back to the
+// configured default.
+func (uc *TrainingJobManager) placementPolicy(name string) (usecase.PlacementPolicy, error) {
+	if name == "" {
+		name = uc.config.PlacementPolicy
+	}
+
+	p, ok := uc.policies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", usecase.ErrUnknownPlacementPolicy, name)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,72 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// ReconcileReport lists the jobs whose resources were adopted or lost by id,
+// and the orphaned resources.
+type ReconcileReport struct {
+	Adopted []string
+	Lost    []string
+	Orphans []entity.Orphan
+}
+
+// Reconciler matches the active jobs in the repos with the containers and
+// twcc resources that are left after a restart. It runs once on startup,
+// before the managers are started, and keeps the orphans it found for the
+// garbage collector.
+type Reconciler struct {
+	training  *TrainingJobManager
+	inference *InferenceJobManager
+
+	mu      sync.Mutex
+	orphans []entity.Orphan
+}
+
+func NewReconciler(t *TrainingJobManager, i *InferenceJobManager) *Reconciler {
+	return &Reconciler{training: t, inference: i}
+}
+
+// Run reconciles all jobs. Jobs of a backend that could not be listed are
+// left alone, the report covers the others.
+func (uc *Reconciler) Run(ctx context.Context) (ReconcileReport, error) {
+	var report ReconcileReport
+
+	err := errors.Join(
+		uc.training.reconcile(ctx, &report),
+		uc.inference.reconcile(&report),
+	)
+
+	sort.Slice(report.Orphans, func(i, j int) bool {
+		if report.Orphans[i].Kind != report.Orphans[j].Kind {
+			return report.Orphans[i].Kind < report.Orphans[j].Kind
+		}
+		return report.Orphans[i].ID < report.Orphans[j].ID
+	})
+
+	uc.mu.Lock()
+	uc.orphans = report.Orphans
+	uc.mu.Unlock()
+
+	if err != nil {
+		return report, fmt.Errorf("Reconciler - Run: %w", err)
+	}
+
+	return report, nil
+}
+
+// Orphans returns the orphans found by the last run.
+func (uc *Reconciler) Orphans() []entity.Orphan {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	return slices.Clone(uc.orphans)
+}
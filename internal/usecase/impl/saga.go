@@ -0,0 +1,67 @@
+package impl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// sagaStep is a step of a saga. compensate, if not nil, undoes the step once
+// it completed and a later step failed.
+type sagaStep struct {
+	name       string
+	run        func() error
+	compensate func() error
+}
+
+// saga runs steps in order and remembers the ones that completed, so they
+// can be rolled back in reverse order if a later step fails.
+type saga struct {
+	steps     []sagaStep
+	completed []sagaStep
+}
+
+// sagaError is the step a saga failed at.
+type sagaError struct {
+	Step string
+	Err  error
+}
+
+func (e *sagaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Step, e.Err)
+}
+
+func (e *sagaError) Unwrap() error {
+	return e.Err
+}
+
+// run runs the steps until one fails, its error is returned as a sagaError.
+func (s *saga) run() error {
+	for _, step := range s.steps {
+		if err := step.run(); err != nil {
+			return &sagaError{Step: step.name, Err: err}
+		}
+
+		s.completed = append(s.completed, step)
+	}
+
+	return nil
+}
+
+// rollback compensates the completed steps, latest first. It keeps going
+// when a compensation fails and returns all failures.
+func (s *saga) rollback() error {
+	var errs []error
+	for i := len(s.completed) - 1; i >= 0; i-- {
+		step := s.completed[i]
+		if step.compensate == nil {
+			continue
+		}
+
+		if err := step.compensate(); err != nil {
+			errs = append(errs, fmt.Errorf("undo %s: %w", step.name, err))
+		}
+	}
+	s.completed = nil
+
+	return errors.Join(errs...)
+}
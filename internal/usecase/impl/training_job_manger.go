@@ -3,105 +3,566 @@ package impl
 import (
 	"context"
 	"fmt"
+	"io"
+	"sort"
+	"sync"
 	"time"
 
+	"golang_backend_template/internal/usecase"
 	"golang_backend_template/internal/usecase/entity"
 	"golang_backend_template/internal/usecase/ports"
 )
 
+// _pullProgressInterval throttles how often the progress of an image pull is
+// persisted.
+const _pullProgressInterval = time.Second
+
 type TrainingJobManager struct {
+	// mu serializes state transitions of the jobs owned by the manager.
+	mu     sync.Mutex
 	repo   ports.TrainingJobsRepo
 	docker ports.ContainerManager
 	twcc   ports.TwccManager
+	// watcher follows the status of submitted twcc jobs.
+	watcher *TwccJobWatcher
+	// events publishes the transitions of the jobs.
+	events *EventBus
+	config SchedulerConfig
+	// policies are the placement policies by name.
+	policies map[string]usecase.PlacementPolicy
+	wake     chan struct{}
 }
 
-func NewTrainingJobManager(m ports.TrainingJobsRepo, d ports.ContainerManager, w ports.TwccManager) *TrainingJobManager {
-	return &TrainingJobManager{
-		repo:   m,
-		docker: d,
-		twcc:   w,
+func NewTrainingJobManager(m ports.TrainingJobsRepo, d ports.ContainerManager, w ports.TwccManager, tw *TwccJobWatcher, e *EventBus, c SchedulerConfig) (*TrainingJobManager, error) {
+	uc := &TrainingJobManager{
+		repo:     m,
+		docker:   d,
+		twcc:     w,
+		watcher:  tw,
+		events:   e,
+		config:   c,
+		policies: NewPlacementPolicies(c.LocalLimit),
+		wake:     make(chan struct{}, 1),
+	}
+
+	if _, err := uc.placementPolicy(""); err != nil {
+		return nil, fmt.Errorf("TrainingJobManager - NewTrainingJobManager - uc.placementPolicy: %w", err)
 	}
+
+	tw.Subscribe(uc.onTwccJobEvent)
+
+	return uc, nil
 }
 
-func (uc *TrainingJobManager) CreateJob(job entity.GenericJob, dockerImageName string, twccJobId string) error {
+// transitionQueuedJob moves a queued job into the next state and persists it.
+// Terminal states also move the job into the history.
+func (uc *TrainingJobManager) transitionQueuedJob(j *entity.QueuedJob, next entity.JobState) error {
+	if err := j.Job.TransitionTo(next); err != nil {
+		return err
+	}
+
+	if err := uc.repo.PushQueuedJob(*j); err != nil {
+		return err
+	}
+	uc.events.publish(entity.JobKindTraining, j.Job)
+
+	if next.IsTerminal() {
+		return uc.repo.DeleteQueuedJob(j.Job.ID)
+	}
+
+	return nil
+}
+
+// transitionContainerJob moves a container job into the next state and persists it.
+// Terminal states also move the job into the history.
+func (uc *TrainingJobManager) transitionContainerJob(j *entity.ContainerJob, next entity.JobState) error {
+	if err := j.Job.TransitionTo(next); err != nil {
+		return err
+	}
+
+	if err := uc.repo.PushContainerJob(*j); err != nil {
+		return err
+	}
+	uc.events.publish(entity.JobKindTraining, j.Job)
+
+	if next.IsTerminal() {
+		return uc.repo.DeleteContainerJob(j.Job.ID)
+	}
+
+	return nil
+}
+
+// transitionTwccJob moves a twcc job into the next state and persists it.
+// Terminal states also move the job into the history.
+func (uc *TrainingJobManager) transitionTwccJob(j *entity.TwccJob, next entity.JobState) error {
+	if err := j.Job.TransitionTo(next); err != nil {
+		return err
+	}
+
+	if err := uc.repo.PushTwccJob(*j); err != nil {
+		return err
+	}
+	uc.events.publish(entity.JobKindTraining, j.Job)
+
+	if next.IsTerminal() {
+		return uc.repo.DeleteTwccJob(j.Job.ID)
+	}
+
+	return nil
+}
+
+// getActiveContainerJob looks up an active container job.
+func (uc *TrainingJobManager) getActiveContainerJob(id string) (entity.ContainerJob, error) {
 	containerJobs, err := uc.repo.GetContainerJobList()
+	if err != nil {
+		return entity.ContainerJob{}, err
+	}
+
+	for _, j := range containerJobs {
+		if j.Job.ID == id {
+			return j, nil
+		}
+	}
+
+	return entity.ContainerJob{}, fmt.Errorf("job %s is not running on docker", id)
+}
 
+// getActiveTwccJob looks up an active twcc job.
+func (uc *TrainingJobManager) getActiveTwccJob(id string) (entity.TwccJob, error) {
+	twccJobs, err := uc.repo.GetTwccJobList()
 	if err != nil {
-		return fmt.Errorf("TrainingJobManager - CreateJob - s.repo.GetTwccJobList: %w", err)
+		return entity.TwccJob{}, err
 	}
 
-	if len(containerJobs) < 2 {
-		job.Status = "running on docker"
-		err = uc.repo.PushContainerJob(entity.ContainerJob{
-			Job:             job,
-			DockerImageName: dockerImageName,
-		})
+	for _, j := range twccJobs {
+		if j.Job.ID == id {
+			return j, nil
+		}
+	}
 
-		if err != nil {
-			return fmt.Errorf("TrainingJobManager - CreateJob - s.repo.CreateContainerJob: %w", err)
+	return entity.TwccJob{}, fmt.Errorf("job %s is not running on twcc", id)
+}
+
+// attachTwccJob records the twcc job created for a provisioning job.
+func (uc *TrainingJobManager) attachTwccJob(j entity.TwccJob, twccJobId string) (entity.TwccJob, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	current, err := uc.getActiveTwccJob(j.Job.ID)
+	if err != nil {
+		return entity.TwccJob{}, err
+	}
+
+	if current.Job.Status != entity.JobStateProvisioning || current.TwccJobId != "" {
+		return entity.TwccJob{}, fmt.Errorf("job %s is no longer waiting for a twcc job", j.Job.ID)
+	}
+
+	current.TwccJobId = twccJobId
+	if err := uc.repo.PushTwccJob(current); err != nil {
+		return entity.TwccJob{}, err
+	}
+
+	return current, nil
+}
+
+// attachContainer records the container created for a provisioning job and
+// marks the job as running.
+func (uc *TrainingJobManager) attachContainer(j entity.ContainerJob, containerID string) (entity.ContainerJob, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	current, err := uc.getActiveContainerJob(j.Job.ID)
+	if err != nil {
+		return entity.ContainerJob{}, err
+	}
+
+	if current.ContainerID != "" {
+		return entity.ContainerJob{}, fmt.Errorf("job %s already runs container %s", j.Job.ID, current.ContainerID)
+	}
+
+	current.ContainerID = containerID
+	current.Job.Phase = ""
+	current.Job.PullProgress = nil
+	if err := uc.transitionContainerJob(&current, entity.JobStateRunning); err != nil {
+		return entity.ContainerJob{}, err
+	}
+
+	return current, nil
+}
+
+// updateContainerJob records the progress of a job that is still
+// provisioning, without moving it into another state.
+func (uc *TrainingJobManager) updateContainerJob(j entity.ContainerJob, update func(*entity.GenericJob)) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	current, err := uc.getActiveContainerJob(j.Job.ID)
+	if err != nil {
+		return err
+	}
+
+	if current.Job.Status != entity.JobStateProvisioning {
+		return fmt.Errorf("job %s is no longer provisioning", j.Job.ID)
+	}
+
+	update(&current.Job)
+
+	return uc.repo.PushContainerJob(current)
+}
+
+// advanceContainerJob reloads the job from the repo, since it may have been
+// cancelled or preempted in the meantime, and moves it into the next state.
+// Updates of a container the job no longer runs are rejected. update, if not
+// nil, records details like the failure reason on the job. Terminal states
+// free a slot, so the dispatcher is woken up.
+func (uc *TrainingJobManager) advanceContainerJob(j entity.ContainerJob, next entity.JobState, update func(*entity.GenericJob)) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	current, err := uc.getActiveContainerJob(j.Job.ID)
+	if err != nil {
+		return err
+	}
+
+	if current.ContainerID != j.ContainerID {
+		return fmt.Errorf("job %s no longer runs container %s", j.Job.ID, j.ContainerID)
+	}
+
+	if update != nil {
+		update(&current.Job)
+	}
+
+	if err := uc.transitionContainerJob(&current, next); err != nil {
+		return err
+	}
+
+	if next.IsTerminal() {
+		uc.wakeDispatcher()
+	}
+
+	return nil
+}
+
+// advanceTwccJob is the twcc counterpart of advanceContainerJob.
+func (uc *TrainingJobManager) advanceTwccJob(j entity.TwccJob, next entity.JobState, update func(*entity.GenericJob)) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	current, err := uc.getActiveTwccJob(j.Job.ID)
+	if err != nil {
+		return err
+	}
+
+	if current.TwccJobId != j.TwccJobId {
+		return fmt.Errorf("job %s no longer runs twcc job %s", j.Job.ID, j.TwccJobId)
+	}
+
+	if update != nil {
+		update(&current.Job)
+	}
+
+	if err := uc.transitionTwccJob(&current, next); err != nil {
+		return err
+	}
+
+	if next.IsTerminal() {
+		uc.wakeDispatcher()
+	}
+
+	return nil
+}
+
+// failedWith records err as the failure reason of a job.
+func failedWith(err error) func(*entity.GenericJob) {
+	return func(job *entity.GenericJob) {
+		job.FailureReason = err.Error()
+	}
+}
+
+// exitState maps how a container exited onto the final state of its job and
+// records the exit details on the job.
+func exitState(exit ports.ContainerExit) (entity.JobState, func(*entity.GenericJob)) {
+	update := func(job *entity.GenericJob) {
+		exitCode := exit.ExitCode
+		job.ExitCode = &exitCode
+		job.OOMKilled = exit.OOMKilled
+
+		switch {
+		case exit.Error != "":
+			job.FailureReason = exit.Error
+		case exit.OOMKilled:
+			job.FailureReason = "container was killed by the OOM killer"
+		case exit.ExitCode != 0:
+			job.FailureReason = fmt.Sprintf("container exited with code %d", exit.ExitCode)
 		}
+	}
 
-		ctx := context.Background()
-		containerID, err := uc.docker.CreateContainer(ctx, dockerImageName)
-		if err != nil {
-			uc.repo.DeleteContainerJob(job.ID)
-			return fmt.Errorf("TrainingJobManager - CreateJob - s.docker.CreateContainerJob: %w", err)
+	if exit.ExitCode != 0 || exit.OOMKilled || exit.Error != "" {
+		return entity.JobStateFailed, update
+	}
+
+	return entity.JobStateSucceeded, update
+}
+
+// containerSpec turns the spec of a job into the spec of its container.
+func containerSpec(spec entity.JobSpec) ports.ContainerSpec {
+	env := make([]string, 0, len(spec.Env))
+	for k, v := range spec.Env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+
+	return ports.ContainerSpec{
+		Image:      spec.Image,
+		Command:    spec.Command,
+		Args:       spec.Args,
+		Env:        env,
+		Mounts:     spec.Mounts,
+		WorkingDir: spec.WorkingDir,
+		CPUs:       spec.CPUs,
+		Memory:     spec.Memory,
+		ShmSize:    spec.ShmSize,
+		Labels:     spec.Labels,
+	}
+}
+
+// pullImage pulls the image of a job and records the progress on the job.
+// The pull is aborted once the job stopped provisioning, e.g. was cancelled.
+func (uc *TrainingJobManager) pullImage(ctx context.Context, j entity.ContainerJob) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	err := uc.updateContainerJob(j, func(job *entity.GenericJob) {
+		job.Phase = entity.JobPhasePullingImage
+	})
+	if err != nil {
+		return err
+	}
+
+	var lastUpdate time.Time
+	err = uc.docker.PullImage(ctx, j.Spec.Image, j.Spec.RegistryCredential, func(p entity.ImagePullProgress) {
+		if time.Since(lastUpdate) < _pullProgressInterval && p.LayersDone < p.Layers {
+			return
 		}
+		lastUpdate = time.Now()
 
-		// function to remove container job from queue after container job is done
-		go uc.docker.ContainerStartWithCallback(ctx, containerID, func() {
-			err := uc.repo.DeleteContainerJob(job.ID)
-			if err != nil {
-				fmt.Errorf("TrainingJobManager - CreateJob - s.repo.DeleteContainerJob: %w", err)
-			}
+		err := uc.updateContainerJob(j, func(job *entity.GenericJob) {
+			job.PullProgress = &p
 		})
+		if err != nil {
+			cancel()
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("pull image %s: %w", j.Spec.Image, err)
+	}
 
-		return nil
+	return nil
+}
+
+// runContainerJob pulls the image, creates and starts the container of a
+// job the dispatcher placed on docker.
+func (uc *TrainingJobManager) runContainerJob(j entity.ContainerJob) {
+	ctx := context.Background()
+	if err := uc.pullImage(ctx, j); err != nil {
+		_ = uc.advanceContainerJob(j, entity.JobStateFailed, failedWith(err))
+		return
 	}
 
-	job.Status = "running on twcc"
-	err = uc.repo.PushTwccJob(entity.TwccJob{
-		Job:       job,
-		TwccJobId: twccJobId,
+	err := uc.updateContainerJob(j, func(job *entity.GenericJob) {
+		job.Phase = entity.JobPhaseCreatingContainer
 	})
 	if err != nil {
-		return fmt.Errorf("TrainingJobManager - CreateJob - s.repo.CreateTwccJob: %w", err)
+		return
 	}
 
-	err = uc.twcc.RunTwccJob(twccJobId)
+	spec := containerSpec(j.Spec)
+	spec.JobID = j.Job.ID
+
+	containerID, err := uc.docker.CreateContainer(ctx, spec)
 	if err != nil {
-		uc.repo.DeleteTwccJob(job.ID)
-		return fmt.Errorf("TrainingJobManager - CreateJob - s.twcc.RunTwccJob: %w", err)
+		_ = uc.advanceContainerJob(j, entity.JobStateFailed, failedWith(err))
+		return
 	}
 
-	// TODO: more elegant way to check if twcc job is done
-	go func() {
-		count := 0
-		for ; count < 10; count++ {
-			time.Sleep(3 * time.Second)
-			status, _ := uc.twcc.GetTwccJobStatus(twccJobId)
-			// if err != nil {
-			// 	fmt.Errorf("TrainingJobManager - CreateJob - s.twcc.GetTwccJobStatus: %w", err)
-			// }
+	j, err = uc.attachContainer(j, containerID)
+	if err != nil {
+		// The job was cancelled while the container was being created.
+		_ = uc.docker.RemoveContainer(ctx, containerID)
+		return
+	}
+
+	uc.startContainer(j)
+}
+
+// startContainer starts the container of a running job and moves the job
+// into the history once the container exited.
+func (uc *TrainingJobManager) startContainer(j entity.ContainerJob) {
+	// function to move container job into the history after container job is done
+	err := uc.docker.ContainerStartWithCallback(context.Background(), j.ContainerID, func(exit ports.ContainerExit) {
+		next, update := exitState(exit)
+		_ = uc.advanceContainerJob(j, next, update)
+	})
+	if err != nil {
+		_ = uc.advanceContainerJob(j, entity.JobStateFailed, failedWith(err))
+	}
+}
+
+// twccJobSpec turns the spec of a job into the spec of its twcc job.
+func twccJobSpec(j entity.TwccJob) ports.TwccJobSpec {
+	spec := containerSpec(j.Spec)
+
+	mounts := make([]ports.TwccMount, 0, len(j.Spec.Mounts))
+	for _, m := range j.Spec.Mounts {
+		mounts = append(mounts, ports.TwccMount{Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly})
+	}
 
-			if status == "Inactive" {
-				_ = uc.repo.DeleteTwccJob(job.ID)
-				// if err != nil {
-				// 	fmt.Errorf("TrainingJobManager - CreateJob - s.repo.DeleteTwccJob: %w", err)
-				// }
-				break
-			}
+	return ports.TwccJobSpec{
+		Name:    "training-" + j.Job.ID,
+		Image:   spec.Image,
+		Command: append(append([]string(nil), spec.Command...), spec.Args...),
+		Env:     spec.Env,
+		Flavor:  j.Spec.Flavor,
+		Project: j.Spec.Project,
+		Mounts:  mounts,
+	}
+}
+
+// runTwccJob creates the twcc job of a job the dispatcher placed on twcc if
+// it has none yet, and submits it. The job stays provisioning until the
+// watcher sees it running on twcc.
+func (uc *TrainingJobManager) runTwccJob(j entity.TwccJob) {
+	if j.TwccJobId == "" {
+		twccJobId, err := uc.twcc.CreateTwccJob(twccJobSpec(j))
+		if err != nil {
+			_ = uc.advanceTwccJob(j, entity.JobStateFailed, failedWith(err))
+			return
 		}
-	}()
+
+		j, err = uc.attachTwccJob(j, twccJobId)
+		if err != nil {
+			// The job was cancelled while its twcc job was being created,
+			// the twcc job was never submitted.
+			return
+		}
+	}
+
+	err := uc.twcc.RunTwccJob(j.TwccJobId)
+	if err != nil {
+		_ = uc.advanceTwccJob(j, entity.JobStateFailed, failedWith(err))
+		return
+	}
+
+	if !uc.watchTwccJob(j) {
+		// The job was cancelled while it was being submitted.
+		_ = uc.twcc.CancelTwccJob(j.TwccJobId)
+	}
+}
+
+// watchTwccJob hands a submitted job over to the watcher, unless it has been
+// cancelled in the meantime.
+func (uc *TrainingJobManager) watchTwccJob(j entity.TwccJob) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	current, err := uc.repo.GetJob(j.Job.ID)
+	if err != nil || current.Status.IsTerminal() {
+		return false
+	}
+
+	uc.watcher.Watch(j.Job.ID, j.TwccJobId)
+
+	return true
+}
+
+// onTwccJobEvent moves a twcc job along with the status reported by twcc.
+func (uc *TrainingJobManager) onTwccJobEvent(e TwccJobEvent) {
+	j := entity.TwccJob{Job: entity.GenericJob{ID: e.JobID}, TwccJobId: e.TwccJobId}
+
+	var update func(*entity.GenericJob)
+	switch {
+	case e.Err != nil:
+		update = failedWith(e.Err)
+	case e.State == entity.JobStateFailed:
+		update = func(job *entity.GenericJob) {
+			job.FailureReason = fmt.Sprintf("twcc job %s reported status %s", e.TwccJobId, e.Status)
+		}
+	case e.State == entity.JobStateSucceeded:
+		// The job may have finished between two polls without ever being
+		// seen running, it did run though.
+		_ = uc.advanceTwccJob(j, entity.JobStateRunning, nil)
+	}
+
+	_ = uc.advanceTwccJob(j, e.State, update)
+}
+
+// CreateJob puts the job into the queue, the dispatcher starts it as soon as
+// one of the backends has a free slot.
+// An empty placementPolicy selects the configured default policy.
+func (uc *TrainingJobManager) CreateJob(job entity.GenericJob, spec entity.JobSpec, twccJobId string, placementPolicy string) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("TrainingJobManager - CreateJob - spec.Validate: %w", err)
+	}
+
+	if spec.Image == "" && twccJobId == "" {
+		return fmt.Errorf("TrainingJobManager - CreateJob - %w: an image or a twcc job is required", entity.ErrInvalidJobSpec)
+	}
+
+	if _, err := uc.placementPolicy(placementPolicy); err != nil {
+		return fmt.Errorf("TrainingJobManager - CreateJob - uc.placementPolicy: %w", err)
+	}
+
+	// Without an image the job can only run on twcc, local-only would keep
+	// it queued forever.
+	if spec.Image == "" && (placementPolicy == usecase.PlacementLocalOnly ||
+		placementPolicy == "" && uc.config.PlacementPolicy == usecase.PlacementLocalOnly) {
+		return fmt.Errorf("TrainingJobManager - CreateJob - %w: an image is required to run on docker", entity.ErrInvalidJobSpec)
+	}
+
+	queuedJob := entity.QueuedJob{
+		Job:             job,
+		Spec:            spec,
+		TwccJobId:       twccJobId,
+		PlacementPolicy: placementPolicy,
+		EnqueuedAt:      time.Now().UTC(),
+	}
+
+	err := uc.transitionQueuedJob(&queuedJob, entity.JobStateQueued)
+	if err != nil {
+		return fmt.Errorf("TrainingJobManager - CreateJob - uc.transitionQueuedJob: %w", err)
+	}
+
+	uc.wakeDispatcher()
 
 	return nil
 }
 
 func (uc *TrainingJobManager) GetJob(id string) (entity.GenericJob, error) {
-	return uc.repo.GetJob(id)
+	job, err := uc.repo.GetJob(id)
+	if err != nil {
+		return entity.GenericJob{}, fmt.Errorf("TrainingJobManager - GetJob - s.repo.GetJob: %w", err)
+	}
+
+	if job.Status == entity.JobStateQueued {
+		positions, err := uc.queuePositions()
+		if err != nil {
+			return entity.GenericJob{}, fmt.Errorf("TrainingJobManager - GetJob - uc.queuePositions: %w", err)
+		}
+		job.QueuePosition = positions[job.ID]
+	}
+
+	return job, nil
 }
 
 func (uc *TrainingJobManager) GetAllJobs() ([]entity.GenericJob, error) {
+	queuedJobs, err := uc.repo.GetQueuedJobList()
+	if err != nil {
+		return nil, fmt.Errorf("TrainingJobManager - GetAllJob - s.repo.GetQueuedJobList: %w", err)
+	}
+
 	containerJobs, err := uc.repo.GetContainerJobList()
 	if err != nil {
 		return nil, fmt.Errorf("TrainingJobManager - GetAllJob - s.repo.GetTwccJobList: %w", err)
@@ -119,6 +580,12 @@ func (uc *TrainingJobManager) GetAllJobs() ([]entity.GenericJob, error) {
 
 	jobs := make([]entity.GenericJob, 0, 64)
 
+	orderQueue(queuedJobs)
+	for i, j := range queuedJobs {
+		j.Job.QueuePosition = i + 1
+		jobs = append(jobs, j.Job)
+	}
+
 	for _, j := range containerJobs {
 		jobs = append(jobs, j.Job)
 	}
@@ -134,27 +601,144 @@ func (uc *TrainingJobManager) GetAllJobs() ([]entity.GenericJob, error) {
 	return jobs, nil
 }
 
+// GetJobLogs streams the logs of the container of a docker job, which are
+// kept after the job finished until the container is removed.
+func (uc *TrainingJobManager) GetJobLogs(ctx context.Context, id string, opts usecase.JobLogOptions) (io.ReadCloser, error) {
+	if _, err := uc.repo.GetJob(id); err != nil {
+		return nil, fmt.Errorf("TrainingJobManager - GetJobLogs - %w: %s", usecase.ErrJobNotFound, id)
+	}
+
+	j, err := uc.repo.GetContainerJob(id)
+	if err != nil || j.ContainerID == "" {
+		return nil, fmt.Errorf("TrainingJobManager - GetJobLogs - %w", usecase.ErrLogsUnavailable)
+	}
+
+	logs, err := uc.docker.ContainerLogs(ctx, j.ContainerID, ports.ContainerLogOptions{
+		Follow:     opts.Follow,
+		Since:      opts.Since,
+		Tail:       opts.Tail,
+		Timestamps: opts.Timestamps,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("TrainingJobManager - GetJobLogs - s.docker.ContainerLogs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// DeleteJob cancels an active job. The job is recorded as cancelled before
+// its container or twcc job is stopped, so that the exit of the container is
+// not mistaken for the job finishing on its own.
 func (uc *TrainingJobManager) DeleteJob(id string) error {
-	containerJobs, err := uc.repo.GetContainerJobList()
+	backend, ref, err := uc.cancel(id)
+	if err != nil {
+		return fmt.Errorf("TrainingJobManager - DeleteJob - uc.cancel: %w", err)
+	}
+
+	// Jobs without a container or twcc job yet are cleaned up by the
+	// goroutine provisioning them once it notices the cancellation.
+	if ref == "" {
+		return nil
+	}
+
+	switch backend {
+	case entity.BackendDocker:
+		err = uc.removeContainer(ref)
+		if err != nil {
+			return fmt.Errorf("TrainingJobManager - DeleteJob - uc.removeContainer: %w", err)
+		}
+	case entity.BackendTwcc:
+		uc.watcher.Unwatch(id)
+		err = uc.twcc.CancelTwccJob(ref)
+		if err != nil {
+			return fmt.Errorf("TrainingJobManager - DeleteJob - s.twcc.CancelTwccJob: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeContainer stops a container gracefully before removing it.
+func (uc *TrainingJobManager) removeContainer(containerID string) error {
+	ctx := context.Background()
+	if err := uc.docker.StopContainer(ctx, containerID); err != nil {
+		return err
+	}
 
+	return uc.docker.RemoveContainer(ctx, containerID)
+}
+
+// cancel moves an active job into the cancelled state. It returns the backend
+// the job was placed on and the id of its container or twcc job, if any.
+func (uc *TrainingJobManager) cancel(id string) (entity.Backend, string, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	queuedJobs, err := uc.repo.GetQueuedJobList()
 	if err != nil {
-		return fmt.Errorf("TrainingJobManager - DeleteJob - s.repo.GetTwccJobList: %w", err)
+		return "", "", fmt.Errorf("s.repo.GetQueuedJobList: %w", err)
 	}
 
-	if len(containerJobs) > 0 {
-		err = uc.repo.DeleteContainerJob(id)
+	for _, j := range queuedJobs {
+		if j.Job.ID != id {
+			continue
+		}
+
+		err = uc.transitionQueuedJob(&j, entity.JobStateCancelled)
 		if err != nil {
-			return fmt.Errorf("TrainingJobManager - DeleteJob - s.repo.DeleteContainerJob: %w", err)
+			return "", "", fmt.Errorf("uc.transitionQueuedJob: %w", err)
 		}
 
-		return nil
+		return "", "", nil
+	}
+
+	containerJobs, err := uc.repo.GetContainerJobList()
+	if err != nil {
+		return "", "", fmt.Errorf("s.repo.GetContainerJobList: %w", err)
+	}
+
+	for _, j := range containerJobs {
+		if j.Job.ID != id {
+			continue
+		}
+
+		err = uc.transitionContainerJob(&j, entity.JobStateCancelled)
+		if err != nil {
+			return "", "", fmt.Errorf("uc.transitionContainerJob: %w", err)
+		}
+
+		uc.wakeDispatcher()
+
+		return entity.BackendDocker, j.ContainerID, nil
+	}
+
+	twccJobs, err := uc.repo.GetTwccJobList()
+	if err != nil {
+		return "", "", fmt.Errorf("s.repo.GetTwccJobList: %w", err)
 	}
 
-	err = uc.repo.DeleteTwccJob(id)
+	for _, j := range twccJobs {
+		if j.Job.ID != id {
+			continue
+		}
+
+		err = uc.transitionTwccJob(&j, entity.JobStateCancelled)
+		if err != nil {
+			return "", "", fmt.Errorf("uc.transitionTwccJob: %w", err)
+		}
+
+		uc.wakeDispatcher()
+
+		// A provisioning twcc job may not have been submitted yet, cancelling
+		// it anyway is harmless and covers the race with runTwccJob.
+		return entity.BackendTwcc, j.TwccJobId, nil
+	}
 
+	// The job is either unknown or already in the history.
+	job, err := uc.repo.GetJob(id)
 	if err != nil {
-		return fmt.Errorf("TrainingJobManager - DeleteJob - s.repo.DeleteTwccJob: %w", err)
+		return "", "", fmt.Errorf("%w: %s", usecase.ErrJobNotFound, id)
 	}
 
-	return nil
+	return "", "", fmt.Errorf("%w: %s -> %s", entity.ErrInvalidTransition, job.Status, entity.JobStateCancelled)
 }
@@ -0,0 +1,75 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/adapter"
+	"golang_backend_template/internal/infra/adapter/twcctest"
+	"golang_backend_template/internal/infra/memo"
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTrainingJobManagerRunsTwccJob(t *testing.T) {
+	s := twcctest.NewServer()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	twcc := adapter.NewTwccAdapter(s.Config())
+	watcher := NewTwccJobWatcher(twcc, TwccWatcherConfig{
+		MinInterval: 5 * time.Millisecond,
+		MaxInterval: 20 * time.Millisecond,
+		MaxErrors:   3,
+	})
+	// Jobs placed remote-only never touch docker.
+	m, err := NewTrainingJobManager(memo.NewTrainingJobsMemory(), nil, twcc, watcher, NewEventBus(16), SchedulerConfig{
+		TwccConcurrency: 1,
+		PlacementPolicy: usecase.PlacementRemoteOnly,
+	})
+	if err != nil {
+		t.Fatalf("NewTrainingJobManager: %v", err)
+	}
+	watcher.Start(ctx)
+	m.Start(ctx)
+
+	spec := entity.JobSpec{Image: "pytorch-24.08-py3:latest", Command: []string{"python"}, Args: []string{"train.py"}}
+	if err := m.CreateJob(entity.NewGenericJob("job-1", "train"), spec, "", ""); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	waitFor(t, "the job to succeed", func() bool {
+		j, err := m.GetJob("job-1")
+		return err == nil && j.Status == entity.JobStateSucceeded
+	})
+
+	j, _ := m.GetJob("job-1")
+	for _, state := range []entity.JobState{entity.JobStateProvisioning, entity.JobStateRunning} {
+		if _, ok := j.EnteredAt(state); !ok {
+			t.Errorf("job never entered %s", state)
+		}
+	}
+
+	twccJob, ok := s.Job("5001")
+	if !ok {
+		t.Fatal("no twcc job was created")
+	}
+	if twccJob.Image != spec.Image || twccJob.Command != "python train.py" || twccJob.Submitted != 1 {
+		t.Errorf("twcc job image %q command %q submitted %d times", twccJob.Image, twccJob.Command, twccJob.Submitted)
+	}
+}
@@ -0,0 +1,196 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+// containerStopped reports whether a docker state is one of a container that
+// will not run again on its own.
+func containerStopped(state string) bool {
+	return state == "exited" || state == "dead"
+}
+
+// reconcile matches the active jobs with the labeled containers and the twcc
+// jobs left after a restart. It runs before Start.
+func (uc *TrainingJobManager) reconcile(ctx context.Context, report *ReconcileReport) error {
+	return errors.Join(
+		uc.reconcileContainers(ctx, report),
+		uc.reconcileTwccJobs(report),
+	)
+}
+
+// reconcileContainers adopts the containers of active docker jobs, a job
+// whose container is gone is lost. Containers missing from the labeled ones
+// are inspected by id, they may predate the label. A container created right before the
+// restart is attached to its job. Containers whose job is unknown, runs
+// another container, or finished while the container still runs are orphans.
+// Stopped containers of finished jobs are kept for their logs.
+func (uc *TrainingJobManager) reconcileContainers(ctx context.Context, report *ReconcileReport) error {
+	containers, err := uc.docker.ListContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("TrainingJobManager - reconcileContainers - uc.docker.ListContainers: %w", err)
+	}
+
+	containerJobs, err := uc.repo.GetContainerJobList()
+	if err != nil {
+		return fmt.Errorf("TrainingJobManager - reconcileContainers - uc.repo.GetContainerJobList: %w", err)
+	}
+
+	byID := make(map[string]ports.ContainerState, len(containers))
+	// live maps a job to a container that did not stop, stopped ones may be
+	// left from before the job was preempted.
+	live := make(map[string]ports.ContainerState, len(containers))
+	for _, c := range containers {
+		byID[c.ID] = c
+		if !containerStopped(c.State) {
+			live[c.JobID] = c
+		}
+	}
+
+	var errs []error
+	owned := make(map[string]bool, len(containerJobs))
+	for _, j := range containerJobs {
+		if j.ContainerID == "" {
+			c, ok := live[j.Job.ID]
+			if !ok {
+				// Start provisions the job again.
+				continue
+			}
+
+			j, err = uc.attachContainer(j, c.ID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("TrainingJobManager - reconcileContainers - uc.attachContainer: %w", err))
+				continue
+			}
+		}
+
+		c, ok := byID[j.ContainerID]
+		if !ok {
+			// Containers created before they were labeled are not listed.
+			c, err = uc.docker.InspectContainer(ctx, j.ContainerID)
+			ok = err == nil
+			if err != nil && !errors.Is(err, ports.ErrContainerNotFound) {
+				errs = append(errs, fmt.Errorf("TrainingJobManager - reconcileContainers - uc.docker.InspectContainer: %w", err))
+				continue
+			}
+		}
+		if !ok {
+			err := uc.advanceContainerJob(j, entity.JobStateLost,
+				failedWith(fmt.Errorf("container %s vanished", j.ContainerID)))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("TrainingJobManager - reconcileContainers - uc.advanceContainerJob: %w", err))
+				continue
+			}
+
+			report.Lost = append(report.Lost, j.Job.ID)
+
+			continue
+		}
+
+		owned[c.ID] = true
+		uc.adoptContainer(j, c)
+		report.Adopted = append(report.Adopted, j.Job.ID)
+	}
+
+	jobs, err := uc.GetAllJobs()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("TrainingJobManager - reconcileContainers - uc.GetAllJobs: %w", err))
+		return errors.Join(errs...)
+	}
+
+	states := make(map[string]entity.JobState, len(jobs))
+	for _, j := range jobs {
+		states[j.ID] = j.Status
+	}
+
+	for _, c := range containers {
+		if owned[c.ID] {
+			continue
+		}
+
+		state, ok := states[c.JobID]
+		var reason string
+		switch {
+		case !ok:
+			reason = "job not found"
+		case !state.IsTerminal():
+			reason = "job no longer runs the container"
+		case !containerStopped(c.State):
+			reason = fmt.Sprintf("job is %s", state)
+		default:
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, entity.Orphan{
+			Kind:   entity.ResourceContainer,
+			ID:     c.ID,
+			JobID:  c.JobID,
+			Reason: reason,
+		})
+	}
+
+	return errors.Join(errs...)
+}
+
+// adoptContainer follows the container of a running job again. A container
+// that was never started is started, one that exited while the service was
+// down finishes its job right away.
+func (uc *TrainingJobManager) adoptContainer(j entity.ContainerJob, c ports.ContainerState) {
+	switch {
+	case c.State == "created":
+		go uc.startContainer(j)
+	case containerStopped(c.State):
+		next, update := exitState(c.Exit)
+		_ = uc.advanceContainerJob(j, next, update)
+	default:
+		go func() {
+			err := uc.docker.WaitContainer(context.Background(), j.ContainerID, func(exit ports.ContainerExit) {
+				next, update := exitState(exit)
+				_ = uc.advanceContainerJob(j, next, update)
+			})
+			if err != nil {
+				_ = uc.advanceContainerJob(j, entity.JobStateFailed, failedWith(err))
+			}
+		}()
+	}
+}
+
+// reconcileTwccJobs marks the jobs whose twcc job is gone as lost, the others
+// are handed to the watcher by Start.
+func (uc *TrainingJobManager) reconcileTwccJobs(report *ReconcileReport) error {
+	twccJobs, err := uc.repo.GetTwccJobList()
+	if err != nil {
+		return fmt.Errorf("TrainingJobManager - reconcileTwccJobs - uc.repo.GetTwccJobList: %w", err)
+	}
+
+	var errs []error
+	for _, j := range twccJobs {
+		if j.TwccJobId == "" {
+			continue
+		}
+
+		_, err := uc.twcc.GetTwccJobStatus(j.TwccJobId)
+		switch {
+		case errors.Is(err, ports.ErrTwccNotFound):
+			err = uc.advanceTwccJob(j, entity.JobStateLost, failedWith(fmt.Errorf("twcc job %s vanished", j.TwccJobId)))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("TrainingJobManager - reconcileTwccJobs - uc.advanceTwccJob: %w", err))
+				continue
+			}
+
+			report.Lost = append(report.Lost, j.Job.ID)
+		case err != nil:
+			// The watcher gives up on the job if twcc keeps failing.
+			errs = append(errs, fmt.Errorf("TrainingJobManager - reconcileTwccJobs - uc.twcc.GetTwccJobStatus: %w", err))
+		default:
+			report.Adopted = append(report.Adopted, j.Job.ID)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package impl
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/adapter"
+	"golang_backend_template/internal/infra/adapter/twcctest"
+	"golang_backend_template/internal/infra/memo"
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+// runningJob stores a docker job that runs containerID.
+func runningJob(t *testing.T, repo *memo.TrainingJobsMemory, id string, containerID string) {
+	t.Helper()
+	job := entity.NewGenericJob(id, id)
+	for _, state := range []entity.JobState{entity.JobStateProvisioning, entity.JobStateRunning} {
+		if err := job.TransitionTo(state); err != nil {
+			t.Fatalf("TransitionTo %s: %v", state, err)
+		}
+	}
+
+	err := repo.PushContainerJob(entity.ContainerJob{Job: job, Spec: entity.JobSpec{Image: "ubuntu:latest"}, ContainerID: containerID})
+	if err != nil {
+		t.Fatalf("PushContainerJob: %v", err)
+	}
+}
+
+func TestReconcileAdoptsUnlabeledContainer(t *testing.T) {
+	s := twcctest.NewServer()
+	defer s.Close()
+
+	twcc := adapter.NewTwccAdapter(s.Config())
+	watcher := NewTwccJobWatcher(twcc, TwccWatcherConfig{MinInterval: time.Hour, MaxInterval: time.Hour, MaxErrors: 3})
+	docker := newFakeDocker()
+	repo := memo.NewTrainingJobsMemory()
+	m, err := NewTrainingJobManager(repo, docker, twcc, watcher, NewEventBus(16), SchedulerConfig{DockerConcurrency: 1, PlacementPolicy: "local-only"})
+	if err != nil {
+		t.Fatalf("NewTrainingJobManager: %v", err)
+	}
+
+	// The container was created before containers were labeled.
+	old, _ := docker.CreateContainer(context.Background(), ports.ContainerSpec{})
+	docker.mu.Lock()
+	docker.containers[old].State = "running"
+	docker.mu.Unlock()
+	runningJob(t, repo, "old", old)
+	runningJob(t, repo, "gone", "container-404")
+
+	var report ReconcileReport
+	if err := m.reconcile(context.Background(), &report); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if !slices.Equal(report.Adopted, []string{"old"}) || !slices.Equal(report.Lost, []string{"gone"}) {
+		t.Fatalf("adopted %v lost %v, want old adopted and gone lost", report.Adopted, report.Lost)
+	}
+
+	docker.stop(old, 0)
+	waitFor(t, "the adopted job to succeed", func() bool {
+		return status(m, "old") == entity.JobStateSucceeded
+	})
+}
@@ -0,0 +1,317 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// _dispatchInterval bounds how long a queued job waits if a wake up is missed,
+// e.g. when a slot is freed by a job finished outside of the manager.
+const _dispatchInterval = 5 * time.Second
+
+// SchedulerConfig caps how many training jobs may run on each backend at once,
+// picks the default placement policy and whether higher priority jobs may
+// preempt running docker jobs.
+type SchedulerConfig struct {
+	DockerConcurrency int
+	TwccConcurrency   int
+	PlacementPolicy   string
+	// LocalLimit caps the docker jobs placed by the local-first policy.
+	LocalLimit int
+	Preemption bool
+}
+
+// Start runs the dispatcher, which moves queued jobs onto a backend as soon as
+// a slot frees up, until ctx is cancelled. Twcc jobs submitted before a
+// restart are handed to the watcher again, jobs that got no container or
+// twcc job before the restart are provisioned again.
+func (uc *TrainingJobManager) Start(ctx context.Context) {
+	if twccJobs, err := uc.repo.GetTwccJobList(); err == nil {
+		for _, j := range twccJobs {
+			if j.TwccJobId == "" {
+				go uc.runTwccJob(j)
+				continue
+			}
+
+			uc.watcher.Watch(j.Job.ID, j.TwccJobId)
+		}
+	}
+
+	if containerJobs, err := uc.repo.GetContainerJobList(); err == nil {
+		for _, j := range containerJobs {
+			if j.ContainerID == "" {
+				go uc.runContainerJob(j)
+			}
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(_dispatchInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = uc.dispatch()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-uc.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// wakeDispatcher asks the dispatcher for another round without blocking.
+func (uc *TrainingJobManager) wakeDispatcher() {
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// orderQueue sorts queued jobs by priority, jobs of the same priority keep
+// their FIFO order.
+func orderQueue(jobs []entity.QueuedJob) {
+	sort.SliceStable(jobs, func(a, b int) bool {
+		return jobs[a].Job.Priority.Rank() > jobs[b].Job.Priority.Rank()
+	})
+}
+
+// dispatch places queued jobs by priority and age. A job that does not fit on
+// any backend blocks the jobs behind it from the backends it waits for, until
+// a slot frees up there, so every backend serves its waiting jobs in order.
+// Jobs that can run elsewhere are still placed. With preemption enabled, the
+// first job waiting for docker may preempt a lower priority docker job.
+func (uc *TrainingJobManager) dispatch() error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	queuedJobs, err := uc.repo.GetQueuedJobList()
+	if err != nil {
+		return fmt.Errorf("TrainingJobManager - dispatch - s.repo.GetQueuedJobList: %w", err)
+	}
+
+	if len(queuedJobs) == 0 {
+		return nil
+	}
+	orderQueue(queuedJobs)
+
+	containerJobs, err := uc.repo.GetContainerJobList()
+	if err != nil {
+		return fmt.Errorf("TrainingJobManager - dispatch - s.repo.GetContainerJobList: %w", err)
+	}
+
+	twccJobs, err := uc.repo.GetTwccJobList()
+	if err != nil {
+		return fmt.Errorf("TrainingJobManager - dispatch - s.repo.GetTwccJobList: %w", err)
+	}
+
+	running := map[entity.Backend]int{
+		entity.BackendDocker: len(containerJobs),
+		entity.BackendTwcc:   len(twccJobs),
+	}
+
+	// blocked holds the backends an earlier job waits for.
+	blocked := make(map[entity.Backend]bool, 2)
+	preemptionTried := false
+
+	for _, j := range queuedJobs {
+		if blocked[entity.BackendDocker] && blocked[entity.BackendTwcc] {
+			return nil
+		}
+
+		policy, err := uc.placementPolicy(j.PlacementPolicy)
+		if err != nil {
+			// The policy was known when the job was created, but has been
+			// removed by a configuration change since.
+			_ = uc.transitionQueuedJob(&j, entity.JobStateFailed)
+			continue
+		}
+
+		loads := uc.backendLoads(j, running)
+		for i := range loads {
+			if blocked[loads[i].Backend] {
+				loads[i].Capacity = 0
+			}
+		}
+
+		backend, ok := policy.Place(j, loads)
+		if !ok {
+			wanted := uc.wantedBackends(j, policy)
+			for _, b := range wanted {
+				blocked[b] = true
+			}
+
+			if uc.config.Preemption && !preemptionTried && containsBackend(wanted, entity.BackendDocker) {
+				preemptionTried = true
+				if err := uc.preempt(j, containerJobs); err != nil {
+					return fmt.Errorf("TrainingJobManager - dispatch - uc.preempt: %w", err)
+				}
+			}
+
+			continue
+		}
+
+		switch backend {
+		case entity.BackendDocker:
+			containerJob := entity.ContainerJob{Job: j.Job, Spec: j.Spec, PlacementPolicy: j.PlacementPolicy}
+			err = uc.transitionContainerJob(&containerJob, entity.JobStateProvisioning)
+			if err != nil {
+				return fmt.Errorf("TrainingJobManager - dispatch - uc.transitionContainerJob: %w", err)
+			}
+
+			go uc.runContainerJob(containerJob)
+		case entity.BackendTwcc:
+			twccJob := entity.TwccJob{Job: j.Job, Spec: j.Spec, TwccJobId: j.TwccJobId}
+			err = uc.transitionTwccJob(&twccJob, entity.JobStateProvisioning)
+			if err != nil {
+				return fmt.Errorf("TrainingJobManager - dispatch - uc.transitionTwccJob: %w", err)
+			}
+
+			go uc.runTwccJob(twccJob)
+		}
+
+		running[backend]++
+	}
+
+	return nil
+}
+
+// backendLoads describes the backends to the placement policy. Jobs without
+// an image cannot run on docker, jobs with neither a twcc job nor an image
+// to create one from cannot run on twcc.
+func (uc *TrainingJobManager) backendLoads(j entity.QueuedJob, running map[entity.Backend]int) []usecase.BackendLoad {
+	dockerCapacity := uc.config.DockerConcurrency
+	if j.Spec.Image == "" {
+		dockerCapacity = 0
+	}
+
+	twccCapacity := uc.config.TwccConcurrency
+	if j.TwccJobId == "" && j.Spec.Image == "" {
+		twccCapacity = 0
+	}
+
+	return []usecase.BackendLoad{
+		{Backend: entity.BackendDocker, Running: running[entity.BackendDocker], Capacity: dockerCapacity},
+		{Backend: entity.BackendTwcc, Running: running[entity.BackendTwcc], Capacity: twccCapacity},
+	}
+}
+
+// wantedBackends returns the backends the policy would start j on if a slot
+// freed up there while the other backend stayed full, j waits for these.
+func (uc *TrainingJobManager) wantedBackends(j entity.QueuedJob, policy usecase.PlacementPolicy) []entity.Backend {
+	var wanted []entity.Backend
+	for _, b := range []entity.Backend{entity.BackendDocker, entity.BackendTwcc} {
+		probe := map[entity.Backend]int{
+			entity.BackendDocker: uc.config.DockerConcurrency,
+			entity.BackendTwcc:   uc.config.TwccConcurrency,
+		}
+		if probe[b] == 0 {
+			continue
+		}
+		probe[b]--
+
+		if backend, ok := policy.Place(j, uc.backendLoads(j, probe)); ok && backend == b {
+			wanted = append(wanted, b)
+		}
+	}
+
+	return wanted
+}
+
+func containsBackend(backends []entity.Backend, b entity.Backend) bool {
+	for _, backend := range backends {
+		if backend == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+// preempt moves the running docker job with the lowest priority below the
+// priority of j back into the queue and removes its container. The dispatcher
+// is woken up once the container is gone, so that j can take over its slot.
+func (uc *TrainingJobManager) preempt(j entity.QueuedJob, containerJobs []entity.ContainerJob) error {
+	var victim *entity.ContainerJob
+
+	for i := range containerJobs {
+		c := &containerJobs[i]
+		if c.Job.Status != entity.JobStateRunning || c.ContainerID == "" {
+			continue
+		}
+
+		if c.Job.Priority.Rank() >= j.Job.Priority.Rank() {
+			continue
+		}
+
+		if victim == nil || c.Job.Priority.Rank() < victim.Job.Priority.Rank() {
+			victim = c
+			continue
+		}
+
+		// Among equal priorities, give up the job that has been running the
+		// shortest time, it loses the least work.
+		cStarted, _ := c.Job.EnteredAt(entity.JobStateRunning)
+		victimStarted, _ := victim.Job.EnteredAt(entity.JobStateRunning)
+		if c.Job.Priority.Rank() == victim.Job.Priority.Rank() && cStarted.After(victimStarted) {
+			victim = c
+		}
+	}
+
+	if victim == nil {
+		return nil
+	}
+
+	// Keep the original enqueue time, the preempted job should not lose its
+	// place to jobs submitted after it.
+	enqueuedAt, ok := victim.Job.EnteredAt(entity.JobStateQueued)
+	if !ok {
+		enqueuedAt = time.Now().UTC()
+	}
+
+	// The preempted job keeps its policy, so it may resume on twcc.
+	queuedJob := entity.QueuedJob{
+		Job:             victim.Job,
+		Spec:            victim.Spec,
+		PlacementPolicy: victim.PlacementPolicy,
+		EnqueuedAt:      enqueuedAt,
+	}
+
+	if err := uc.transitionQueuedJob(&queuedJob, entity.JobStateQueued); err != nil {
+		return err
+	}
+
+	// The job gets a new container when it resumes, the stopped one is
+	// removed.
+	containerID := victim.ContainerID
+	go func() {
+		_ = uc.removeContainer(containerID)
+		uc.wakeDispatcher()
+	}()
+
+	return nil
+}
+
+// queuePositions maps the id of every queued job to its 1-based position.
+func (uc *TrainingJobManager) queuePositions() (map[string]int, error) {
+	queuedJobs, err := uc.repo.GetQueuedJobList()
+	if err != nil {
+		return nil, err
+	}
+
+	orderQueue(queuedJobs)
+
+	positions := make(map[string]int, len(queuedJobs))
+	for i, j := range queuedJobs {
+		positions[j.Job.ID] = i + 1
+	}
+
+	return positions, nil
+}
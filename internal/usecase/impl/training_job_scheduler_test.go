@@ -0,0 +1,458 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/adapter"
+	"golang_backend_template/internal/infra/adapter/twcctest"
+	"golang_backend_template/internal/infra/memo"
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+// fakeContainer is a container of fakeDocker, done is closed once it
+// stopped.
+type fakeContainer struct {
+	ports.ContainerState
+	done chan struct{}
+}
+
+// fakeDocker runs containers until they are stopped or finished by the test.
+type fakeDocker struct {
+	mu         sync.Mutex
+	next       int
+	containers map[string]*fakeContainer
+	removed    []string
+}
+
+func newFakeDocker() *fakeDocker {
+	return &fakeDocker{containers: make(map[string]*fakeContainer)}
+}
+
+func (d *fakeDocker) PullImage(context.Context, string, string, func(entity.ImagePullProgress)) error {
+	return nil
+}
+
+func (d *fakeDocker) CreateContainer(_ context.Context, spec ports.ContainerSpec) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.next++
+	id := fmt.Sprintf("container-%d", d.next)
+	d.containers[id] = &fakeContainer{
+		ContainerState: ports.ContainerState{ID: id, JobID: spec.JobID, State: "created"},
+		done:           make(chan struct{}),
+	}
+
+	return id, nil
+}
+
+func (d *fakeDocker) container(id string) (*fakeContainer, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, ok := d.containers[id]
+	if !ok {
+		return nil, errors.New("no such container")
+	}
+
+	return c, nil
+}
+
+func (d *fakeDocker) ContainerStartWithCallback(ctx context.Context, id string, callback func(ports.ContainerExit)) error {
+	c, err := d.container(id)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	c.State = "running"
+	d.mu.Unlock()
+
+	return d.WaitContainer(ctx, id, callback)
+}
+
+func (d *fakeDocker) WaitContainer(ctx context.Context, id string, callback func(ports.ContainerExit)) error {
+	c, err := d.container(id)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+	}
+
+	d.mu.Lock()
+	exit := c.Exit
+	d.mu.Unlock()
+	callback(exit)
+
+	return nil
+}
+
+// stop ends a running container with the given exit code.
+func (d *fakeDocker) stop(id string, exitCode int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, ok := d.containers[id]
+	if !ok || c.State == "exited" {
+		return
+	}
+
+	c.State = "exited"
+	c.Exit = ports.ContainerExit{ExitCode: exitCode}
+	c.FinishedAt = time.Now()
+	close(c.done)
+}
+
+// running returns the ids of the jobs with a running container.
+func (d *fakeDocker) running() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	jobs := make(map[string]string)
+	for _, c := range d.containers {
+		if c.State == "running" {
+			jobs[c.JobID] = c.ID
+		}
+	}
+
+	return jobs
+}
+
+// ListContainers leaves out the unlabeled containers, like docker filtering
+// by the job id label.
+func (d *fakeDocker) ListContainers(context.Context) ([]ports.ContainerState, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	states := make([]ports.ContainerState, 0, len(d.containers))
+	for _, c := range d.containers {
+		if c.JobID != "" {
+			states = append(states, c.ContainerState)
+		}
+	}
+
+	return states, nil
+}
+
+func (d *fakeDocker) InspectContainer(_ context.Context, id string) (ports.ContainerState, error) {
+	c, err := d.container(id)
+	if err != nil {
+		return ports.ContainerState{}, fmt.Errorf("%w: %s", ports.ErrContainerNotFound, id)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return c.ContainerState, nil
+}
+
+func (d *fakeDocker) StopContainer(_ context.Context, id string) error {
+	if _, err := d.container(id); err != nil {
+		return err
+	}
+
+	d.stop(id, 137)
+
+	return nil
+}
+
+func (d *fakeDocker) RemoveContainer(_ context.Context, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.containers[id]; !ok {
+		return errors.New("no such container")
+	}
+
+	delete(d.containers, id)
+	d.removed = append(d.removed, id)
+
+	return nil
+}
+
+func (d *fakeDocker) ListDanglingImages(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (d *fakeDocker) RemoveImage(context.Context, string) error {
+	return nil
+}
+
+func (d *fakeDocker) ContainerLogs(context.Context, string, ports.ContainerLogOptions) (io.ReadCloser, error) {
+	return nil, errors.New("no logs")
+}
+
+// newScheduler starts a training job manager on fakeDocker and a fake twcc
+// gateway.
+func newScheduler(t *testing.T, c SchedulerConfig) (*TrainingJobManager, *fakeDocker, *memo.TrainingJobsMemory) {
+	t.Helper()
+	s := twcctest.NewServer()
+	t.Cleanup(s.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	twcc := adapter.NewTwccAdapter(s.Config())
+	watcher := NewTwccJobWatcher(twcc, TwccWatcherConfig{MinInterval: time.Hour, MaxInterval: time.Hour, MaxErrors: 3})
+	docker := newFakeDocker()
+	repo := memo.NewTrainingJobsMemory()
+	m, err := NewTrainingJobManager(repo, docker, twcc, watcher, NewEventBus(16), c)
+	if err != nil {
+		t.Fatalf("NewTrainingJobManager: %v", err)
+	}
+	m.Start(ctx)
+
+	return m, docker, repo
+}
+
+// createJob queues a docker job with the given priority and placement policy.
+func createJob(t *testing.T, m *TrainingJobManager, id string, priority entity.PriorityClass, policy string) {
+	t.Helper()
+	job := entity.NewGenericJob(id, id)
+	job.Priority = priority
+	if err := m.CreateJob(job, entity.JobSpec{Image: "ubuntu:latest"}, "", policy); err != nil {
+		t.Fatalf("CreateJob %s: %v", id, err)
+	}
+}
+
+// onTwcc reports whether a job was placed on twcc.
+func onTwcc(repo *memo.TrainingJobsMemory, id string) bool {
+	jobs, _ := repo.GetTwccJobList()
+	for _, j := range jobs {
+		if j.Job.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestPreemptedJobKeepsPolicyAndLosesContainer(t *testing.T) {
+	m, docker, repo := newScheduler(t, SchedulerConfig{
+		DockerConcurrency: 1,
+		TwccConcurrency:   1,
+		PlacementPolicy:   "local-first",
+		LocalLimit:        1,
+		Preemption:        true,
+	})
+
+	createJob(t, m, "low", entity.PriorityLow, "")
+	waitFor(t, "low to run on docker", func() bool {
+		_, ok := docker.running()["low"]
+		return ok
+	})
+	lowContainer := docker.running()["low"]
+
+	createJob(t, m, "high", entity.PriorityHigh, "local-only")
+	waitFor(t, "high to run on docker", func() bool {
+		_, ok := docker.running()["high"]
+		return ok
+	})
+
+	// low was queued local-first, so it spills over to twcc.
+	waitFor(t, "low to move to twcc", func() bool {
+		return onTwcc(repo, "low")
+	})
+
+	docker.mu.Lock()
+	defer docker.mu.Unlock()
+	if len(docker.removed) != 1 || docker.removed[0] != lowContainer {
+		t.Errorf("removed containers %v, want the preempted %s", docker.removed, lowContainer)
+	}
+}
+
+// status returns the state of a job, empty if it is unknown.
+func status(m *TrainingJobManager, id string) entity.JobState {
+	j, err := m.GetJob(id)
+	if err != nil {
+		return ""
+	}
+
+	return j.Status
+}
+
+func TestDispatchDoesNotBlockOtherBackends(t *testing.T) {
+	m, docker, repo := newScheduler(t, SchedulerConfig{
+		DockerConcurrency: 1,
+		TwccConcurrency:   1,
+		PlacementPolicy:   "local-only",
+	})
+
+	createJob(t, m, "a", entity.PriorityNormal, "")
+	waitFor(t, "a to run on docker", func() bool {
+		_, ok := docker.running()["a"]
+		return ok
+	})
+
+	// b waits for docker, c behind it only runs on twcc.
+	createJob(t, m, "b", entity.PriorityNormal, "")
+	createJob(t, m, "c", entity.PriorityNormal, "remote-only")
+	waitFor(t, "c to be placed on twcc", func() bool {
+		return onTwcc(repo, "c")
+	})
+
+	// d queued after b waits behind b for docker.
+	createJob(t, m, "d", entity.PriorityNormal, "")
+	if s := status(m, "b"); s != entity.JobStateQueued {
+		t.Fatalf("b is %s, want queued", s)
+	}
+
+	docker.stop(docker.running()["a"], 0)
+	waitFor(t, "b to run on docker", func() bool {
+		_, ok := docker.running()["b"]
+		return ok
+	})
+	if s := status(m, "d"); s != entity.JobStateQueued {
+		t.Errorf("d is %s, want queued behind b", s)
+	}
+}
+
+// runsOnDocker reports whether a job has a running container.
+func runsOnDocker(docker *fakeDocker, id string) func() bool {
+	return func() bool {
+		_, ok := docker.running()[id]
+		return ok
+	}
+}
+
+// queuePosition returns the queue position GetJob reports for a job.
+func queuePosition(t *testing.T, m *TrainingJobManager, id string) int {
+	t.Helper()
+	j, err := m.GetJob(id)
+	if err != nil {
+		t.Fatalf("GetJob %s: %v", id, err)
+	}
+
+	return j.QueuePosition
+}
+
+func TestDispatchOrdersByPriorityThenFIFO(t *testing.T) {
+	m, docker, _ := newScheduler(t, SchedulerConfig{
+		DockerConcurrency: 1,
+		TwccConcurrency:   1,
+		PlacementPolicy:   "local-only",
+	})
+
+	createJob(t, m, "first", entity.PriorityNormal, "")
+	waitFor(t, "first to run on docker", runsOnDocker(docker, "first"))
+
+	createJob(t, m, "normal-1", entity.PriorityNormal, "")
+	createJob(t, m, "low", entity.PriorityLow, "")
+	createJob(t, m, "high", entity.PriorityHigh, "")
+	createJob(t, m, "normal-2", entity.PriorityNormal, "")
+
+	want := []string{"high", "normal-1", "normal-2", "low"}
+	for i, id := range want {
+		if got := queuePosition(t, m, id); got != i+1 {
+			t.Errorf("%s at queue position %d, want %d", id, got, i+1)
+		}
+	}
+	if got := queuePosition(t, m, "first"); got != 0 {
+		t.Errorf("running job at queue position %d, want none", got)
+	}
+
+	running := "first"
+	for _, id := range want {
+		docker.stop(docker.running()[running], 0)
+		waitFor(t, id+" to run on docker", runsOnDocker(docker, id))
+		running = id
+
+		if n := len(docker.running()); n != 1 {
+			t.Fatalf("%d containers running, want 1", n)
+		}
+	}
+}
+
+func TestFreedSlotWakesDispatcher(t *testing.T) {
+	m, docker, _ := newScheduler(t, SchedulerConfig{
+		DockerConcurrency: 1,
+		TwccConcurrency:   1,
+		PlacementPolicy:   "local-only",
+	})
+
+	createJob(t, m, "a", entity.PriorityNormal, "")
+	waitFor(t, "a to run on docker", runsOnDocker(docker, "a"))
+	createJob(t, m, "b", entity.PriorityNormal, "")
+
+	// Let the dispatcher settle with b waiting.
+	time.Sleep(50 * time.Millisecond)
+	if s := status(m, "b"); s != entity.JobStateQueued {
+		t.Fatalf("b is %s, want queued", s)
+	}
+
+	// b must start long before the next periodic dispatch.
+	released := time.Now()
+	docker.stop(docker.running()["a"], 0)
+	waitFor(t, "b to run on docker", runsOnDocker(docker, "b"))
+	if waited := time.Since(released); waited > _dispatchInterval/2 {
+		t.Errorf("b started %s after the slot freed up, the dispatcher was not woken up", waited)
+	}
+	if got := queuePosition(t, m, "b"); got != 0 {
+		t.Errorf("running job at queue position %d, want none", got)
+	}
+}
+
+func TestPreemptionPicksLowestPriorityShortestRunning(t *testing.T) {
+	m, docker, _ := newScheduler(t, SchedulerConfig{
+		DockerConcurrency: 3,
+		TwccConcurrency:   1,
+		PlacementPolicy:   "local-only",
+		Preemption:        true,
+	})
+
+	for _, j := range []struct {
+		id       string
+		priority entity.PriorityClass
+	}{
+		{"normal", entity.PriorityNormal},
+		{"low-old", entity.PriorityLow},
+		{"low-new", entity.PriorityLow},
+	} {
+		createJob(t, m, j.id, j.priority, "")
+		waitFor(t, j.id+" to run on docker", runsOnDocker(docker, j.id))
+		// Tell the start times apart.
+		time.Sleep(10 * time.Millisecond)
+	}
+	victim := docker.running()["low-new"]
+
+	createJob(t, m, "high", entity.PriorityHigh, "")
+	waitFor(t, "high to run on docker", runsOnDocker(docker, "high"))
+
+	if s := status(m, "low-new"); s != entity.JobStateQueued {
+		t.Errorf("low-new is %s, want queued again", s)
+	}
+	for _, id := range []string{"normal", "low-old"} {
+		if !runsOnDocker(docker, id)() {
+			t.Errorf("%s was preempted, want low-new", id)
+		}
+	}
+
+	docker.mu.Lock()
+	defer docker.mu.Unlock()
+	if len(docker.removed) != 1 || docker.removed[0] != victim {
+		t.Errorf("removed containers %v, want %s of low-new", docker.removed, victim)
+	}
+}
+
+func TestPreemptionSparesEqualPriority(t *testing.T) {
+	m, docker, _ := newScheduler(t, SchedulerConfig{
+		DockerConcurrency: 1,
+		TwccConcurrency:   1,
+		PlacementPolicy:   "local-only",
+		Preemption:        true,
+	})
+
+	createJob(t, m, "a", entity.PriorityHigh, "")
+	waitFor(t, "a to run on docker", runsOnDocker(docker, "a"))
+	createJob(t, m, "b", entity.PriorityHigh, "")
+
+	time.Sleep(50 * time.Millisecond)
+	if !runsOnDocker(docker, "a")() || status(m, "b") != entity.JobStateQueued {
+		t.Errorf("a running %t b %s, want b waiting for a", runsOnDocker(docker, "a")(), status(m, "b"))
+	}
+}
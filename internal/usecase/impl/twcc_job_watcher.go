@@ -0,0 +1,274 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+)
+
+// _twccInactiveGrace is how many polls a submitted job may still be reported
+// Inactive before it is taken as finished, twcc reports the status of a job
+// submitted a moment ago with a delay.
+const _twccInactiveGrace = 3
+
+// TwccWatcherConfig tunes the polling of twcc jobs. A job is polled every
+// MinInterval after its status changed, the interval doubles up to
+// MaxInterval while the status stays the same. A job whose status could not
+// be read MaxErrors times in a row is reported as failed.
+type TwccWatcherConfig struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	MaxErrors   int
+}
+
+// TwccJobEvent reports that a watched twcc job moved into another state.
+type TwccJobEvent struct {
+	JobID     string
+	TwccJobId string
+	// Status is the status reported by twcc and State the job state it maps
+	// to.
+	Status string
+	State  entity.JobState
+	// Err explains why the job is reported as failed without twcc saying so.
+	Err error
+}
+
+type watchedTwccJob struct {
+	jobID     string
+	twccJobId string
+	status    string
+	state     entity.JobState
+	// active is set once twcc reported any status but Inactive.
+	active   bool
+	polls    int
+	errors   int
+	interval time.Duration
+	nextPoll time.Time
+}
+
+// TwccJobWatcher polls the status of all submitted twcc jobs and notifies its
+// subscribers whenever one of them changes state. Jobs are dropped once they
+// reached a terminal state.
+type TwccJobWatcher struct {
+	twcc   ports.TwccManager
+	config TwccWatcherConfig
+
+	mu          sync.Mutex
+	jobs        map[string]*watchedTwccJob
+	subscribers []func(TwccJobEvent)
+	wake        chan struct{}
+}
+
+func NewTwccJobWatcher(w ports.TwccManager, c TwccWatcherConfig) *TwccJobWatcher {
+	if c.MaxInterval < c.MinInterval {
+		c.MaxInterval = c.MinInterval
+	}
+
+	return &TwccJobWatcher{
+		twcc:   w,
+		config: c,
+		jobs:   make(map[string]*watchedTwccJob),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Subscribe registers fn to be called with every event. fn is called from
+// the polling goroutine and must not block for long.
+func (w *TwccJobWatcher) Subscribe(fn func(TwccJobEvent)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Watch starts polling the twcc job of a job that was just submitted.
+func (w *TwccJobWatcher) Watch(jobID string, twccJobId string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.jobs[jobID] = &watchedTwccJob{
+		jobID:     jobID,
+		twccJobId: twccJobId,
+		state:     entity.JobStateProvisioning,
+		interval:  w.config.MinInterval,
+		nextPoll:  time.Now().Add(w.config.MinInterval),
+	}
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Unwatch stops polling a job, e.g. once it was cancelled.
+func (w *TwccJobWatcher) Unwatch(jobID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.jobs, jobID)
+}
+
+// Start polls the watched jobs until ctx is cancelled.
+func (w *TwccJobWatcher) Start(ctx context.Context) {
+	go func() {
+		timer := time.NewTimer(w.config.MinInterval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.wake:
+			case <-timer.C:
+			}
+
+			wait := w.poll(ctx)
+
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wait)
+		}
+	}()
+}
+
+// poll polls the jobs that are due and returns how long to wait until the
+// next job is due.
+func (w *TwccJobWatcher) poll(ctx context.Context) time.Duration {
+	w.mu.Lock()
+	now := time.Now()
+	due := make([]watchedTwccJob, 0, len(w.jobs))
+	for _, j := range w.jobs {
+		if !j.nextPoll.After(now) {
+			due = append(due, *j)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, j := range due {
+		if ctx.Err() != nil {
+			break
+		}
+
+		status, err := w.twcc.GetTwccJobStatus(j.twccJobId)
+		if event, ok := w.update(j.jobID, status, err); ok {
+			w.publish(event)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wait := w.config.MaxInterval
+	now = time.Now()
+	for _, j := range w.jobs {
+		if d := j.nextPoll.Sub(now); d < wait {
+			wait = max(d, 0)
+		}
+	}
+
+	return wait
+}
+
+// update records the outcome of polling a job and returns an event if the
+// job changed state.
+func (w *TwccJobWatcher) update(jobID string, status string, err error) (TwccJobEvent, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	j, ok := w.jobs[jobID]
+	if !ok {
+		// The job was unwatched while it was being polled.
+		return TwccJobEvent{}, false
+	}
+
+	j.polls++
+	event := TwccJobEvent{JobID: j.jobID, TwccJobId: j.twccJobId, Status: status}
+
+	if err != nil {
+		j.errors++
+		j.interval = min(j.interval*2, w.config.MaxInterval)
+		j.nextPoll = time.Now().Add(j.interval)
+
+		switch {
+		case errors.Is(err, ports.ErrTwccNotFound):
+			event.Err = fmt.Errorf("twcc job %s is gone: %w", j.twccJobId, err)
+		case j.errors >= w.config.MaxErrors:
+			event.Err = fmt.Errorf("twcc job %s could not be polled %d times: %w", j.twccJobId, j.errors, err)
+		default:
+			return TwccJobEvent{}, false
+		}
+
+		event.State = entity.JobStateFailed
+		delete(w.jobs, jobID)
+
+		return event, true
+	}
+
+	j.errors = 0
+	if !strings.EqualFold(status, "inactive") {
+		j.active = true
+	}
+
+	state, known := twccJobState(status, j)
+	if !known || state == j.state {
+		j.interval = min(j.interval*2, w.config.MaxInterval)
+		j.nextPoll = time.Now().Add(j.interval)
+
+		return TwccJobEvent{}, false
+	}
+
+	j.status = status
+	j.state = state
+	j.interval = w.config.MinInterval
+	j.nextPoll = time.Now().Add(j.interval)
+	if state.IsTerminal() {
+		delete(w.jobs, jobID)
+	}
+
+	event.State = state
+
+	return event, true
+}
+
+// twccJobState maps the status twcc reports for a job onto a job state.
+// Inactive is reported both before a job started and after it finished, so
+// it only counts as finished once the job has been seen active or the grace
+// period after the submit has passed.
+func twccJobState(status string, j *watchedTwccJob) (entity.JobState, bool) {
+	switch strings.ToLower(status) {
+	case "queued", "pending", "waiting", "submitted", "creating":
+		return entity.JobStateProvisioning, true
+	case "running", "active":
+		return entity.JobStateRunning, true
+	case "completed", "succeeded", "finished":
+		return entity.JobStateSucceeded, true
+	case "failed", "error":
+		return entity.JobStateFailed, true
+	case "cancelled", "canceled":
+		return entity.JobStateCancelled, true
+	case "inactive":
+		if j.active || j.polls > _twccInactiveGrace {
+			return entity.JobStateSucceeded, true
+		}
+
+		return j.state, true
+	}
+
+	return "", false
+}
+
+func (w *TwccJobWatcher) publish(event TwccJobEvent) {
+	w.mu.Lock()
+	subscribers := slices.Clone(w.subscribers)
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
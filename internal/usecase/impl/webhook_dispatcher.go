@@ -0,0 +1,362 @@
+package impl
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"golang_backend_template/internal/usecase"
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/internal/usecase/ports"
+	"golang_backend_template/pkg/logger"
+)
+
+// _webhookListInterval is how long to wait before listing the pending
+// deliveries again after it failed.
+const _webhookListInterval = time.Minute
+
+// Headers of a webhook call. The signature is the hex HMAC-SHA256 of the
+// timestamp, a dot and the body, keyed with the secret of the webhook.
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookConfig configures the delivery of webhooks. A call fails after
+// Timeout, failed deliveries are retried with an exponential backoff from
+// BaseDelay up to MaxDelay, until MaxAttempts calls failed.
+type WebhookConfig struct {
+	Timeout     time.Duration
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// WebhookDispatcher calls the webhook of a job once the job reached a
+// terminal state. Deliveries are persisted before they are attempted, so
+// pending ones are resumed after a restart.
+type WebhookDispatcher struct {
+	repo   ports.WebhookRepo
+	events *EventBus
+	client *http.Client
+	config WebhookConfig
+	l      logger.Interface
+	wake   chan struct{}
+}
+
+func NewWebhookDispatcher(r ports.WebhookRepo, e *EventBus, c WebhookConfig, l logger.Interface) *WebhookDispatcher {
+	c.MaxAttempts = max(c.MaxAttempts, 1)
+
+	return &WebhookDispatcher{
+		repo:   r,
+		events: e,
+		client: &http.Client{Timeout: c.Timeout},
+		config: c,
+		l:      l,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+func (uc *WebhookDispatcher) RegisterWebhook(w entity.Webhook) error {
+	if err := w.Validate(); err != nil {
+		return fmt.Errorf("WebhookDispatcher - RegisterWebhook - w.Validate: %w", err)
+	}
+
+	if err := uc.repo.StoreWebhook(w); err != nil {
+		return fmt.Errorf("WebhookDispatcher - RegisterWebhook - uc.repo.StoreWebhook: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *WebhookDispatcher) UnregisterWebhook(jobID string) error {
+	if err := uc.repo.DeleteWebhook(jobID); err != nil {
+		return fmt.Errorf("WebhookDispatcher - UnregisterWebhook - uc.repo.DeleteWebhook: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *WebhookDispatcher) GetWebhookDeliveries(jobID string) (entity.Webhook, []entity.WebhookDelivery, error) {
+	w, err := uc.repo.GetWebhook(jobID)
+	if errors.Is(err, ports.ErrWebhookNotFound) {
+		return entity.Webhook{}, nil, fmt.Errorf("WebhookDispatcher - GetWebhookDeliveries - %w: %s", usecase.ErrWebhookNotFound, jobID)
+	}
+	if err != nil {
+		return entity.Webhook{}, nil, fmt.Errorf("WebhookDispatcher - GetWebhookDeliveries - uc.repo.GetWebhook: %w", err)
+	}
+	w.Secret = ""
+
+	deliveries, err := uc.repo.GetWebhookDeliveries(jobID)
+	if err != nil {
+		return entity.Webhook{}, nil, fmt.Errorf("WebhookDispatcher - GetWebhookDeliveries - uc.repo.GetWebhookDeliveries: %w", err)
+	}
+
+	return w, deliveries, nil
+}
+
+// Start subscribes to the job events right away, so transitions published
+// after it returns are not missed, and delivers until ctx is cancelled.
+func (uc *WebhookDispatcher) Start(ctx context.Context) {
+	events := uc.events.Subscribe(ctx, usecase.JobEventFilter{}, 0)
+
+	go uc.listen(ctx, events)
+	go uc.deliverLoop(ctx)
+}
+
+// listen records a delivery for every terminal transition of a job with a
+// webhook. A subscription dropped for falling behind is resumed after the
+// last event seen.
+func (uc *WebhookDispatcher) listen(ctx context.Context, events <-chan entity.JobEvent) {
+	var last uint64
+	for {
+		for e := range events {
+			last = e.ID
+			if e.To.IsTerminal() {
+				uc.enqueue(ctx, e)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		events = uc.events.Subscribe(ctx, usecase.JobEventFilter{}, last)
+	}
+}
+
+// enqueue records a delivery of e if its job has a webhook. Failing repo
+// calls are retried until they succeed or ctx is cancelled, so the event is
+// not lost.
+func (uc *WebhookDispatcher) enqueue(ctx context.Context, e entity.JobEvent) {
+	// The ID is picked once, a retried store does not record the delivery
+	// twice.
+	id := uuid.New().String()
+
+	for attempt := 0; ; attempt++ {
+		if uc.tryEnqueue(e, id) {
+			return
+		}
+
+		if !uc.sleep(ctx, uc.delay(attempt)) {
+			return
+		}
+	}
+}
+
+// tryEnqueue records a delivery of e and reports whether it is done, jobs
+// without a webhook are done right away.
+func (uc *WebhookDispatcher) tryEnqueue(e entity.JobEvent, id string) bool {
+	w, err := uc.repo.GetWebhook(e.JobID)
+	if errors.Is(err, ports.ErrWebhookNotFound) {
+		return true
+	}
+	if err != nil {
+		uc.l.Error(err, "WebhookDispatcher - enqueue - uc.repo.GetWebhook")
+		return false
+	}
+
+	now := time.Now().UTC()
+	d := entity.WebhookDelivery{
+		ID:            id,
+		EventID:       e.ID,
+		JobID:         e.JobID,
+		URL:           w.URL,
+		Event:         e,
+		Status:        entity.WebhookDeliveryPending,
+		Attempts:      []entity.WebhookAttempt{},
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := uc.repo.StoreWebhookDelivery(d); err != nil {
+		uc.l.Error(err, "WebhookDispatcher - enqueue - uc.repo.StoreWebhookDelivery")
+		return false
+	}
+
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// sleep waits for d and reports false if ctx was cancelled first.
+func (uc *WebhookDispatcher) sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// deliverLoop attempts the pending deliveries that are due, then sleeps
+// until the next one is due or a new delivery is recorded.
+func (uc *WebhookDispatcher) deliverLoop(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-uc.wake:
+		}
+
+		next, err := uc.deliverDue(ctx)
+		if err != nil {
+			next = time.Now().Add(_webhookListInterval)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if !next.IsZero() {
+			timer.Reset(max(time.Until(next), 0))
+		}
+	}
+}
+
+// deliverDue attempts the pending deliveries that are due and returns when
+// the next one is due, zero if none is pending.
+func (uc *WebhookDispatcher) deliverDue(ctx context.Context) (time.Time, error) {
+	deliveries, err := uc.repo.GetPendingWebhookDeliveries()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var next time.Time
+	for _, d := range deliveries {
+		if ctx.Err() != nil {
+			return time.Time{}, nil
+		}
+
+		if !time.Now().Before(d.NextAttemptAt) {
+			d = uc.deliver(ctx, d)
+		}
+		if d.Status == entity.WebhookDeliveryPending && (next.IsZero() || d.NextAttemptAt.Before(next)) {
+			next = d.NextAttemptAt
+		}
+	}
+
+	return next, nil
+}
+
+// delay returns how long to wait after the given failed attempt.
+func (uc *WebhookDispatcher) delay(attempt int) time.Duration {
+	if attempt >= 32 {
+		return uc.config.MaxDelay
+	}
+
+	return min(uc.config.BaseDelay<<attempt, uc.config.MaxDelay)
+}
+
+// deliver attempts a delivery and records the attempt. A delivery is done
+// once the webhook answered with a 2xx status, it fails once it used up its
+// attempts.
+func (uc *WebhookDispatcher) deliver(ctx context.Context, d entity.WebhookDelivery) entity.WebhookDelivery {
+	attempt := entity.WebhookAttempt{At: time.Now().UTC()}
+	attempt.StatusCode, attempt.Error = uc.call(ctx, d)
+	if ctx.Err() != nil {
+		// Shutting down, the delivery is attempted again after the restart.
+		return d
+	}
+
+	d.Attempts = append(d.Attempts, attempt)
+	switch {
+	case attempt.Error == "":
+		d.Status = entity.WebhookDeliveryDelivered
+	case len(d.Attempts) >= uc.config.MaxAttempts:
+		d.Status = entity.WebhookDeliveryFailed
+	default:
+		d.NextAttemptAt = time.Now().UTC().Add(uc.delay(len(d.Attempts) - 1))
+	}
+
+	// A delivery that stays pending in the repo is sent again, so the store
+	// is retried until it succeeds.
+	for retry := 0; ; retry++ {
+		err := uc.repo.StoreWebhookDelivery(d)
+		if err == nil {
+			break
+		}
+
+		uc.l.Error(err, "WebhookDispatcher - deliver - uc.repo.StoreWebhookDelivery")
+		if !uc.sleep(ctx, uc.delay(retry)) {
+			break
+		}
+	}
+
+	return d
+}
+
+// call posts the event of a delivery to its webhook, signed with the secret
+// of the webhook if it has one. It returns the status code of the response
+// and why the call failed, if it did.
+func (uc *WebhookDispatcher) call(ctx context.Context, d entity.WebhookDelivery) (int, string) {
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, d.ID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+
+	w, err := uc.repo.GetWebhook(d.JobID)
+	if err != nil {
+		return 0, err.Error()
+	}
+	if w.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+signWebhook(w.Secret, timestamp, body))
+	}
+
+	resp, err := uc.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Sprintf("webhook answered %s", resp.Status)
+	}
+
+	return resp.StatusCode, ""
+}
+
+// signWebhook returns the hex HMAC-SHA256 of timestamp.body keyed with
+// secret.
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
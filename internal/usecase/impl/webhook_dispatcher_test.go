@@ -0,0 +1,99 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang_backend_template/internal/infra/memo"
+	"golang_backend_template/internal/usecase/entity"
+	"golang_backend_template/pkg/logger"
+)
+
+// flakyWebhookRepo fails the next stores of pending and of finished
+// deliveries.
+type flakyWebhookRepo struct {
+	*memo.WebhooksMemory
+
+	mu          sync.Mutex
+	failPending int
+	failDone    int
+}
+
+func (r *flakyWebhookRepo) StoreWebhookDelivery(d entity.WebhookDelivery) error {
+	r.mu.Lock()
+	fail := &r.failDone
+	if d.Status == entity.WebhookDeliveryPending {
+		fail = &r.failPending
+	}
+	if *fail > 0 {
+		*fail--
+		r.mu.Unlock()
+		return errors.New("database is locked")
+	}
+	r.mu.Unlock()
+
+	return r.WebhooksMemory.StoreWebhookDelivery(d)
+}
+
+func TestWebhookDispatcherRetriesFailedStores(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, req.Header.Get(WebhookIDHeader))
+	}))
+	defer hook.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &flakyWebhookRepo{WebhooksMemory: memo.NewWebhooksMemory(), failPending: 2, failDone: 1}
+	events := NewEventBus(16)
+	d := NewWebhookDispatcher(repo, events, WebhookConfig{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		BaseDelay:   5 * time.Millisecond,
+		MaxDelay:    20 * time.Millisecond,
+	}, logger.New("error"))
+	d.Start(ctx)
+
+	if err := d.RegisterWebhook(entity.Webhook{JobID: "job-1", Kind: entity.JobKindTraining, URL: hook.URL}); err != nil {
+		t.Fatalf("RegisterWebhook: %v", err)
+	}
+
+	job := entity.NewGenericJob("job-1", "train")
+	_ = job.TransitionTo(entity.JobStateCancelled)
+	events.publish(entity.JobKindTraining, job)
+
+	waitFor(t, "the delivery to be stored as delivered", func() bool {
+		_, deliveries, err := d.GetWebhookDeliveries("job-1")
+		return err == nil && len(deliveries) == 1 && deliveries[0].Status == entity.WebhookDeliveryDelivered
+	})
+
+	_, deliveries, _ := d.GetWebhookDeliveries("job-1")
+	if deliveries[0].ID == "" || deliveries[0].EventID != deliveries[0].Event.ID {
+		t.Errorf("delivery id %q event id %d, want an id of its own and the id of the event", deliveries[0].ID, deliveries[0].EventID)
+	}
+
+	repo.mu.Lock()
+	if repo.failPending != 0 || repo.failDone != 0 {
+		t.Errorf("%d pending and %d finished stores were not attempted", repo.failPending, repo.failDone)
+	}
+	repo.mu.Unlock()
+
+	// Give a duplicate send a chance to show up.
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 1 || calls[0] != deliveries[0].ID {
+		t.Errorf("webhook called with ids %v, want once with %s", calls, deliveries[0].ID)
+	}
+}
@@ -1,10 +1,55 @@
 package usecase
 
-import "golang_backend_template/internal/usecase/entity"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+var (
+	ErrInferenceNotReady = errors.New("inference job did not become ready")
+	ErrJobFinished       = errors.New("job already finished")
+)
+
+// InferenceNotReadyError reports a CCS that failed or was not reachable
+// before the deadline, it matches ErrInferenceNotReady.
+type InferenceNotReadyError struct {
+	TwccCCSId string
+	// Status is the last status twcc reported for the CCS.
+	Status string
+	// TimedOut is set if the deadline passed, otherwise the CCS failed.
+	TimedOut bool
+}
+
+func (e *InferenceNotReadyError) Error() string {
+	if e.TimedOut {
+		return fmt.Sprintf("%s: ccs %s timed out in status %q", ErrInferenceNotReady, e.TwccCCSId, e.Status)
+	}
+
+	return fmt.Sprintf("%s: ccs %s failed with status %q", ErrInferenceNotReady, e.TwccCCSId, e.Status)
+}
+
+func (e *InferenceNotReadyError) Is(target error) bool {
+	return target == ErrInferenceNotReady
+}
 
 type InferenceJobRequester interface {
-	CreateJob(job entity.GenericJob) (string, error)
-	GetJob(id string) (entity.GenericJob, error)
+	// CreateJob queues an inference job for provisioning, an invalid spec
+	// fails with entity.ErrInvalidJobSpec. The job moves to running once its
+	// entry point is reachable, or fails with the reason of an
+	// InferenceNotReadyError if its CCS does not become ready.
+	// The job is torn down once it ran for its ttl, 0 picks the default ttl.
+	CreateJob(job entity.GenericJob, spec entity.InferenceSpec, ttl time.Duration) error
+	// RenewJob extends the lease of a job by ttl from now, 0 renews it by
+	// its own ttl, and returns when the job expires.
+	RenewJob(id string, ttl time.Duration) (time.Time, error)
+	// GetJob returns a job together with the number of requests proxied
+	// to it.
+	GetJob(id string) (entity.InferenceJob, error)
 	GetAllJobs() ([]entity.GenericJob, error)
 	DeleteJob(jobID string) error
+	// CountProxyRequest counts a request proxied to the entry point of a job.
+	CountProxyRequest(id string)
 }
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// JobEventFilter selects job events, empty fields match every event. States
+// match the state a job moved into.
+type JobEventFilter struct {
+	JobIDs []string
+	Kinds  []entity.JobKind
+	States []entity.JobState
+}
+
+func (f JobEventFilter) Matches(e entity.JobEvent) bool {
+	return matchesAny(f.JobIDs, e.JobID) && matchesAny(f.Kinds, e.Kind) && matchesAny(f.States, e.To)
+}
+
+func matchesAny[T comparable](allowed []T, v T) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, a := range allowed {
+		if a == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+type JobEventRequester interface {
+	// Subscribe streams the job events matching filter until ctx is done.
+	// Buffered events after lastEventID are replayed first, 0 replays
+	// nothing. The channel is closed early if the subscriber falls behind,
+	// it can resume from the last event it received.
+	Subscribe(ctx context.Context, filter JobEventFilter, lastEventID uint64) <-chan entity.JobEvent
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"errors"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+// Names of the built-in placement policies.
+const (
+	PlacementLocalFirst  = "local-first"
+	PlacementRemoteOnly  = "remote-only"
+	PlacementLocalOnly   = "local-only"
+	PlacementRoundRobin  = "round-robin"
+	PlacementLeastLoaded = "least-loaded"
+)
+
+var ErrUnknownPlacementPolicy = errors.New("unknown placement policy")
+
+// BackendLoad is the view of a backend a placement policy decides on. A
+// backend the job cannot run on, e.g. twcc without a twcc job, has no capacity.
+type BackendLoad struct {
+	Backend  entity.Backend
+	Running  int
+	Capacity int
+}
+
+func (l BackendLoad) HasCapacity() bool {
+	return l.Running < l.Capacity
+}
+
+// PlacementPolicy decides which backend a queued training job is started on.
+type PlacementPolicy interface {
+	// Place returns the backend to start the job on, or false to keep the job
+	// in the queue until the load changes.
+	Place(job entity.QueuedJob, loads []BackendLoad) (entity.Backend, bool)
+}
@@ -2,11 +2,92 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
+
+	"golang_backend_template/internal/usecase/entity"
 )
 
+// JobIDLabel labels the containers and CCS created for a job with its id, so
+// they can be matched with their job again after a restart.
+const JobIDLabel = "go-web-template.job-id"
+
+var ErrContainerNotFound = errors.New("container not found")
+
 type (
+	// ContainerLogOptions selects the container logs to read, Since accepts
+	// a timestamp or a relative duration like 10m and Tail a line count or all.
+	ContainerLogOptions struct {
+		Follow     bool
+		Since      string
+		Tail       string
+		Timestamps bool
+	}
+
+	// ContainerSpec is everything needed to create a container. Command
+	// replaces the entrypoint of the image and Args its default arguments,
+	// Env holds KEY=value pairs. JobID is set as the JobIDLabel of the
+	// container.
+	ContainerSpec struct {
+		JobID      string
+		Image      string
+		Command    []string
+		Args       []string
+		Env        []string
+		Mounts     []entity.Mount
+		WorkingDir string
+		CPUs       float64
+		Memory     int64
+		ShmSize    int64
+		Labels     map[string]string
+	}
+
+	// ContainerExit describes how a container stopped.
+	ContainerExit struct {
+		ExitCode  int64
+		OOMKilled bool
+		Error     string
+	}
+
+	// ContainerState is a container labeled with a job id. State is the
+	// docker state like created, running or exited, Exit and FinishedAt are
+	// only set for containers that stopped.
+	ContainerState struct {
+		ID         string
+		JobID      string
+		State      string
+		Exit       ContainerExit
+		FinishedAt time.Time
+	}
+
 	ContainerManager interface {
-		CreateContainer(context.Context, string) (string, error)
-		ContainerStartWithCallback(context.Context, string, func()) error
+		// PullImage pulls an image with the named registry credentials, or
+		// the ones configured for its registry if credential is empty, and
+		// reports the progress of the pull until it finished.
+		PullImage(ctx context.Context, image string, credential string, progress func(entity.ImagePullProgress)) error
+		// CreateContainer expects the image of the spec to be pulled.
+		CreateContainer(context.Context, ContainerSpec) (string, error)
+		// ContainerStartWithCallback starts a container and blocks until it
+		// stopped, the callback receives how the container exited.
+		ContainerStartWithCallback(context.Context, string, func(ContainerExit)) error
+		// WaitContainer blocks until a started container stopped, the
+		// callback receives how the container exited.
+		WaitContainer(context.Context, string, func(ContainerExit)) error
+		// ListContainers returns all containers labeled with a job id,
+		// including stopped ones.
+		ListContainers(context.Context) ([]ContainerState, error)
+		// InspectContainer returns the state of a container whether it is
+		// labeled or not, a missing container fails with
+		// ErrContainerNotFound. JobID is empty for unlabeled containers.
+		InspectContainer(context.Context, string) (ContainerState, error)
+		StopContainer(context.Context, string) error
+		RemoveContainer(context.Context, string) error
+		// ListDanglingImages returns the ids of the images pulled through
+		// PullImage that lost their tag to a newer pull of the same tag.
+		ListDanglingImages(context.Context) ([]string, error)
+		RemoveImage(context.Context, string) error
+		// ContainerLogs returns the demultiplexed stdout and stderr of a container.
+		ContainerLogs(context.Context, string, ContainerLogOptions) (io.ReadCloser, error)
 	}
 )
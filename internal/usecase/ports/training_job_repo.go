@@ -2,13 +2,21 @@ package ports
 
 import "golang_backend_template/internal/usecase/entity"
 
+// TrainingJobsRepo keeps every active job in exactly one of the queued,
+// container or twcc lists, pushing a job moves it out of the others.
+// Deleting an active job moves it into the history.
 type TrainingJobsRepo interface {
+	PushQueuedJob(entity.QueuedJob) error
 	PushContainerJob(entity.ContainerJob) error
 	PushTwccJob(entity.TwccJob) error
 	GetJob(string) (entity.GenericJob, error)
+	// GetContainerJob returns an active or finished container job.
+	GetContainerJob(string) (entity.ContainerJob, error)
+	GetQueuedJobList() ([]entity.QueuedJob, error)
 	GetTwccJobList() ([]entity.TwccJob, error)
 	GetContainerJobList() ([]entity.ContainerJob, error)
 	GetHistoryJobList() ([]entity.GenericJob, error)
+	DeleteQueuedJob(string) error
 	DeleteTwccJob(string) error
 	DeleteContainerJob(string) error
 }
@@ -0,0 +1,46 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of TWCC failures, a TwccError matches its kind with errors.Is.
+var (
+	ErrTwccNotFound      = errors.New("twcc: not found")
+	ErrTwccUnauthorized  = errors.New("twcc: unauthorized")
+	ErrTwccQuotaExceeded = errors.New("twcc: quota exceeded")
+	ErrTwccRateLimited   = errors.New("twcc: rate limited")
+	// ErrTwccTransient failures may succeed when retried later.
+	ErrTwccTransient = errors.New("twcc: transient failure")
+	ErrTwccPermanent = errors.New("twcc: permanent failure")
+	// ErrTwccCCSNotReady is returned for a CCS that has no public IP or does
+	// not expose the port yet.
+	ErrTwccCCSNotReady = errors.New("twcc: ccs not ready")
+)
+
+// TwccError is a request the TWCC API answered with an error.
+type TwccError struct {
+	Kind       error
+	StatusCode int
+	// Detail is the message of the response body.
+	Detail string
+}
+
+func (e *TwccError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s (status %d)", e.Kind, e.StatusCode)
+	}
+
+	return fmt.Sprintf("%s (status %d): %s", e.Kind, e.StatusCode, e.Detail)
+}
+
+func (e *TwccError) Unwrap() error {
+	return e.Kind
+}
+
+// IsTwccRetryable reports whether a TWCC call failed for a reason that may go
+// away, like rate limiting or an unavailable gateway.
+func IsTwccRetryable(err error) bool {
+	return errors.Is(err, ErrTwccTransient) || errors.Is(err, ErrTwccRateLimited)
+}
@@ -1,14 +1,75 @@
 package ports
 
+// PoolSpecLabel labels the CCS of the inference pool with a fingerprint of
+// their spec, so a restarted pool can take them back.
+const PoolSpecLabel = "go-web-template.pool-spec"
+
 type (
+	// TwccMount mounts a path of the twcc storage into a job.
+	TwccMount struct {
+		Source   string
+		Target   string
+		ReadOnly bool
+	}
+
+	// TwccJobSpec is everything needed to create a twcc job, an empty Flavor
+	// or Project falls back to the configured defaults.
+	TwccJobSpec struct {
+		Name    string
+		Image   string
+		Command []string
+		Env     []string
+		Flavor  string
+		Project string
+		Mounts  []TwccMount
+	}
+
+	// TwccCCSSpec is everything needed to create a CCS, Mounts map the twcc
+	// storage (gpfs01, gpfs02) to its mount path. An empty Project falls back
+	// to the configured default. JobID labels the CCS with the job it runs,
+	// PoolSpec with the spec fingerprint of a pool CCS.
+	TwccCCSSpec struct {
+		JobID    string
+		Name     string
+		Image    string
+		Flavor   string
+		Replica  int
+		Project  string
+		Mounts   map[string]string
+		PoolSpec string
+	}
+
+	// TwccCCS is a CCS found by ListTwccCCS, JobID is empty for CCS that
+	// were not created for a job and PoolSpec for CCS not created by the
+	// pool.
+	TwccCCS struct {
+		ID       string
+		Name     string
+		JobID    string
+		PoolSpec string
+		Status   string
+	}
+
 	TwccManager interface {
 		// 任務容器
+		// CreateTwccJob creates a twcc job and returns its id, the job still
+		// has to be submitted with RunTwccJob.
+		CreateTwccJob(TwccJobSpec) (string, error)
 		RunTwccJob(string) error
 		GetTwccJobStatus(string) (string, error)
+		CancelTwccJob(string) error
 		// 開發容器
-		CreateTwccCCS() (string, error)
-		TwccCCSAssociateIP(string) error
-		GetTwccCCSEntryPoint(string) (string, error)
+		CreateTwccCCS(TwccCCSSpec) (string, error)
+		// GetTwccCCSStatus returns the status of a CCS, like Pending or Ready.
+		GetTwccCCSStatus(string) (string, error)
+		// TwccCCSAssociateIP exposes the given container ports on a public IP.
+		TwccCCSAssociateIP(string, []int) error
+		// GetTwccCCSEntryPoint returns the public address of a container
+		// port, or ErrTwccCCSNotReady while it is not associated yet.
+		GetTwccCCSEntryPoint(string, int) (string, error)
 		DeleteTwccCCS(string) error
+		// ListTwccCCS returns the CCS of a project, an empty project falls
+		// back to the configured default.
+		ListTwccCCS(string) ([]TwccCCS, error)
 	}
 )
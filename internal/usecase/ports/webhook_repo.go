@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"errors"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+type WebhookRepo interface {
+	StoreWebhook(entity.Webhook) error
+	// GetWebhook returns the webhook of a job, ErrWebhookNotFound if the job
+	// has none.
+	GetWebhook(jobID string) (entity.Webhook, error)
+	DeleteWebhook(jobID string) error
+	StoreWebhookDelivery(entity.WebhookDelivery) error
+	// GetWebhookDeliveries returns the deliveries of a job, oldest first.
+	GetWebhookDeliveries(jobID string) ([]entity.WebhookDelivery, error)
+	GetPendingWebhookDeliveries() ([]entity.WebhookDelivery, error)
+}
@@ -1,10 +1,34 @@
 package usecase
 
-import "golang_backend_template/internal/usecase/entity"
+import (
+	"context"
+	"errors"
+	"io"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+var (
+	ErrJobNotFound     = errors.New("job not found")
+	ErrLogsUnavailable = errors.New("logs are only available for jobs started on docker")
+)
+
+// JobLogOptions selects the logs of a job, Since accepts a timestamp or a
+// relative duration like 10m and Tail a line count or all.
+type JobLogOptions struct {
+	Follow     bool
+	Since      string
+	Tail       string
+	Timestamps bool
+}
 
 type TrainingJobRequester interface {
-	CreateJob(job entity.GenericJob, dockerImageName string, twccJobId string) error
+	// CreateJob queues a job that runs spec on docker, or the twcc job
+	// twccJobId on twcc. An invalid spec fails with entity.ErrInvalidJobSpec.
+	CreateJob(job entity.GenericJob, spec entity.JobSpec, twccJobId string, placementPolicy string) error
 	GetJob(id string) (entity.GenericJob, error)
 	GetAllJobs() ([]entity.GenericJob, error)
+	GetJobLogs(ctx context.Context, id string, opts JobLogOptions) (io.ReadCloser, error)
+	// DeleteJob cancels an active job and stops whatever runs it.
 	DeleteJob(jobID string) error
 }
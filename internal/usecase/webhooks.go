@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"errors"
+
+	"golang_backend_template/internal/usecase/entity"
+)
+
+var ErrWebhookNotFound = errors.New("job has no webhook")
+
+type WebhookRequester interface {
+	// RegisterWebhook calls the webhook once its job reached a terminal
+	// state, an invalid webhook returns entity.ErrInvalidWebhook.
+	RegisterWebhook(entity.Webhook) error
+	// UnregisterWebhook drops the webhook of a job that could not be
+	// created.
+	UnregisterWebhook(jobID string) error
+	// GetWebhookDeliveries returns the webhook of a job without its secret
+	// and its deliveries, ErrWebhookNotFound if the job has none.
+	GetWebhookDeliveries(jobID string) (entity.Webhook, []entity.WebhookDelivery, error)
+}